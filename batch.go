@@ -0,0 +1,121 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 08:21:44 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Outcome of running the pipeline on a single input file in a batch
+type FileResult struct {
+	InFile  string
+	OutFile string
+	// Number of statements generated from the file
+	Statements int
+	// Number of empty cells left out of the statements
+	Skipped int
+	Err     error
+}
+
+// Expands an input path into the files it refers to
+// Directories expand to the regular, non hidden files directly inside them with an extension the parser reads,
+// so stray files such as a README don't fail the run, and globs expand to all of their sorted matches
+func ExpandInputs(p string, parser string) ([]string, error) {
+	if p == StdStream {
+		return []string{p}, nil
+	}
+	if strings.ContainsAny(p, "*?[") {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files found matching %s", p)
+		}
+		sort.Strings(matches)
+		return matches, nil
+	}
+	info, err := os.Stat(p)
+	if err != nil || !info.IsDir() {
+		return []string{p}, nil
+	}
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") && parserReadsExtension(parser, filepath.Ext(e.Name())) {
+			out = append(out, filepath.Join(p, e.Name()))
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no files read by parser %s found in directory %s", cmp.Or(parser, DefaultParser), p)
+	}
+	return out, nil
+}
+
+// Reports if an output path is a naming pattern, which gives each input file its own output file
+func isOutputPattern(p string) bool {
+	return strings.Contains(p, "{stem}") || strings.Contains(p, "{name}") || strings.Contains(p, "{ext}")
+}
+
+// Fills in an output naming pattern for an input file
+// {stem} is the input file name without its extension, {name} is the full file name, and {ext} is the extension without its dot
+func outputPath(pattern string, in string) string {
+	name := filepath.Base(in)
+	if in == StdStream {
+		name = "stdin"
+	}
+	ext := filepath.Ext(name)
+	return strings.NewReplacer(
+		"{stem}", strings.TrimSuffix(name, ext),
+		"{name}", name,
+		"{ext}", strings.TrimPrefix(ext, "."),
+	).Replace(pattern)
+}
+
+// Checks an output naming pattern gives every input file its own output file, since later files would overwrite earlier ones
+// Inputs such as a.csv and a.tsv share a {stem}, and need a pattern using {name} or {ext} to tell them apart
+func checkOutputPaths(pattern string, inputs []string) error {
+	if !isOutputPattern(pattern) {
+		return nil
+	}
+	seen := map[string]string{}
+	for _, in := range inputs {
+		p := outputPath(pattern, in)
+		prev, ok := seen[p]
+		if ok {
+			return fmt.Errorf("%s and %s both write to %s, use {name} or {ext} in the outfile to tell them apart", prev, in, p)
+		}
+		seen[p] = in
+	}
+	return nil
+}
+
+// Writes a per file summary of a batch run
+func writeSummary(w io.Writer, results []FileResult) {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: %v\n", r.InFile, r.Err)
+			continue
+		}
+		if r.Skipped > 0 {
+			fmt.Fprintf(w, "ok   %s -> %s (%d statements, %d empty cells skipped)\n", r.InFile, r.OutFile, r.Statements, r.Skipped)
+			continue
+		}
+		fmt.Fprintf(w, "ok   %s -> %s (%d statements)\n", r.InFile, r.OutFile, r.Statements)
+	}
+	fmt.Fprintf(w, "%d of %d files succeeded\n", len(results)-failed, len(results))
+}
@@ -0,0 +1,228 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 08:21:44 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.csv", "a.csv", "c.tsv", ".hidden.csv", "README.md", "notes.txt", "d.json", "E.CSV"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte{}, 0o644)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	err := os.Mkdir(filepath.Join(dir, "nested"), 0o755)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	in := func(names ...string) []string {
+		out := []string{}
+		for _, name := range names {
+			out = append(out, filepath.Join(dir, name))
+		}
+		return out
+	}
+
+	table := []struct {
+		path   string
+		parser string
+		exp    []string
+		err    bool
+	}{
+		{"-", "CSV", []string{"-"}, false},
+		{"data/test1.csv", "CSV", []string{"data/test1.csv"}, false},
+		{"data/test1.csv", "HTML", []string{"data/test1.csv"}, false},
+		{"data/missing.csv", "CSV", []string{"data/missing.csv"}, false},
+		{dir, "CSV", in("E.CSV", "a.csv", "b.csv"), false},
+		{dir, "", in("E.CSV", "a.csv", "b.csv"), false},
+		{dir, "TSV", in("c.tsv"), false},
+		{dir, "JSONArrArr", in("d.json"), false},
+		{dir, "JSONArrObj", in("d.json"), false},
+		{dir, ParserAuto, in("E.CSV", "README.md", "a.csv", "b.csv", "c.tsv", "d.json"), false},
+		{dir, "XLSX", nil, true},
+		{filepath.Join(dir, "*.csv"), "CSV", in(".hidden.csv", "a.csv", "b.csv"), false},
+		{filepath.Join(dir, "*.txt"), "CSV", in("notes.txt"), false},
+		{filepath.Join(dir, "*.xlsx"), "XLSX", nil, true},
+		{filepath.Join(dir, "nested"), ParserAuto, nil, true},
+	}
+
+	for _, test := range table {
+		res, err := ExpandInputs(test.path, test.parser)
+		if (err != nil) != test.err {
+			t.Errorf("ExpandInputs(%v, %v) returned error %v, expected error %v", test.path, test.parser, err, test.err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("ExpandInputs(%v, %v) = %v, expected %v", test.path, test.parser, res, test.exp)
+		}
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	table := []struct {
+		pattern string
+		in      string
+		exp     string
+	}{
+		{"outputs/{stem}.txt", "data/test1.csv", "outputs/test1.txt"},
+		{"outputs/{ext}/{name}.txt", "data/test1.csv", "outputs/csv/test1.csv.txt"},
+		{"outputs/{stem}.txt", "-", "outputs/stdin.txt"},
+		{"outputs/output.txt", "data/test1.csv", "outputs/output.txt"},
+	}
+
+	for _, test := range table {
+		res := outputPath(test.pattern, test.in)
+		if res != test.exp {
+			t.Errorf("outputPath(%v, %v) = %v, expected %v", test.pattern, test.in, res, test.exp)
+		}
+	}
+}
+
+func TestCheckOutputPaths(t *testing.T) {
+	table := []struct {
+		pattern string
+		inputs  []string
+		err     bool
+	}{
+		{"outputs/{stem}.txt", []string{"data/a.csv", "data/b.csv"}, false},
+		{"outputs/{stem}.txt", []string{"data/a.csv", "data/a.tsv"}, true},
+		{"outputs/{stem}_{ext}.txt", []string{"data/a.csv", "data/a.tsv"}, false},
+		{"outputs/{name}.txt", []string{"data/a.csv", "other/a.csv"}, true},
+		{"outputs/combined.txt", []string{"data/a.csv", "data/a.tsv"}, false},
+	}
+
+	for _, test := range table {
+		err := checkOutputPaths(test.pattern, test.inputs)
+		if (err != nil) != test.err {
+			t.Errorf("checkOutputPaths(%v, %v) returned error %v, expected error %v", test.pattern, test.inputs, err, test.err)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	fields := FormatFields{Link: "for", Eq: "is", ValLabel: "price"}
+	config := ConfigFields{InFile: "data/test[12].csv", Formatter: "UnnamedCoordFormatter1", Parser: "CSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(1)}
+
+	dir := t.TempDir()
+	config.OutFile = filepath.Join(dir, "{stem}.txt")
+	results, err := RunBatch(config, fields)
+	if err != nil {
+		t.Errorf("RunBatch(%v) returned error %v", config.InFile, err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("RunBatch(%v) returned %v results, expected 2", config.InFile, len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil || r.Statements == 0 {
+			t.Errorf("RunBatch(%v) result for %v = %+v, expected statements and no error", config.InFile, r.InFile, r)
+		}
+		_, err := os.Stat(r.OutFile)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+
+	config.InFile = "data/test1.*"
+	_, err = RunBatch(config, fields)
+	if err == nil {
+		t.Errorf("RunBatch(%v) with outfile %v returned no error, expected an error for inputs sharing a stem", config.InFile, config.OutFile)
+	}
+
+	config.OutFile = filepath.Join(dir, "combined.txt")
+	config.InFile = "data/test[1_]*.json"
+	config.Parser = "JSONArrObj"
+	results, err = RunBatch(config, fields)
+	if err != nil {
+		t.Errorf("RunBatch(%v) returned error %v", config.InFile, err)
+	}
+	failed := []string{}
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r.InFile)
+		}
+	}
+	if len(results) < 3 || len(failed) == 0 || len(failed) == len(results) {
+		t.Errorf("RunBatch(%v) failed on %v of %v files, expected a mix of successes and failures", config.InFile, failed, len(results))
+	}
+	res, err := os.ReadFile(config.OutFile)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	exp := "data/test_arr_obj1.json: price for row1 and col1 is val11"
+	if !strings.Contains(string(res), exp) {
+		t.Errorf("RunBatch(%v) wrote %q, expected it to contain %q", config.InFile, res, exp)
+	}
+
+	err = Run(config, fields)
+	if err == nil {
+		t.Errorf("Run(%v) returned no error, expected an error for the failed files", config.InFile)
+	}
+
+	config = ConfigFields{InFile: "data/test[12].csv", Formatter: "TemplateFormatter", Parser: "CSV", OutFile: filepath.Join(dir, "{stem}.txt")}
+	for _, tmpl := range []string{"{{.Value", "{{.Row}}"} {
+		results, err = RunBatch(config, FormatFields{Template: tmpl})
+		if err != nil {
+			t.Errorf("RunBatch(%v) with template %q returned error %v", config.InFile, tmpl, err)
+		}
+		for _, r := range results {
+			if r.Err == nil {
+				t.Errorf("RunBatch(%v) with template %q result for %v = %+v, expected an error", config.InFile, tmpl, r.InFile, r)
+			}
+		}
+	}
+
+	config = ConfigFields{InFile: "data/test_nulls.csv", Formatter: "UnnamedCoordFormatter1", Parser: "CSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(1), EmptyCells: EmptySkip}
+	config.OutFile = filepath.Join(dir, "{stem}.txt")
+	results, err = RunBatch(config, fields)
+	if err != nil || results[0].Statements != 2 || results[0].Skipped != 4 {
+		t.Errorf("RunBatch(%v) = %+v, %v, expected 2 statements and 4 skipped cells", config.InFile, results, err)
+	}
+	var summary strings.Builder
+	writeSummary(&summary, results)
+	exp = "(2 statements, 4 empty cells skipped)"
+	if !strings.Contains(summary.String(), exp) {
+		t.Errorf("writeSummary(%+v) = %q, expected it to contain %q", results, summary.String(), exp)
+	}
+}
+
+func TestRunBatchJobs(t *testing.T) {
+	fields := FormatFields{Link: "for", Eq: "is", ValLabel: "price"}
+	config := ConfigFields{InFile: "data/test*", Formatter: "NamedCoordFormatter1", Parser: ParserAuto, Table: TableAll}
+
+	config.OutFile = filepath.Join(t.TempDir(), "combined.txt")
+	outputs := []string{}
+	summaries := []string{}
+	for _, jobs := range []int{1, 4, 16} {
+		config.Jobs = jobs
+		results, err := RunBatch(config, fields)
+		if err != nil {
+			t.Fatalf("RunBatch(%v) with %v jobs returned error %v", config.InFile, jobs, err)
+		}
+		res, err := os.ReadFile(config.OutFile)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		var summary strings.Builder
+		writeSummary(&summary, results)
+		outputs = append(outputs, string(res))
+		summaries = append(summaries, summary.String())
+	}
+
+	for i := range outputs {
+		if outputs[i] != outputs[0] {
+			t.Errorf("RunBatch(%v) output differs between job counts", config.InFile)
+		}
+		if summaries[i] != summaries[0] {
+			t.Errorf("RunBatch(%v) summary with job count %v = %v, expected %v", config.InFile, i, summaries[i], summaries[0])
+		}
+	}
+}
@@ -6,6 +6,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 )
@@ -26,92 +27,360 @@ type FormatFields struct {
 	XLabel string `json:"x_label,omitempty"`
 	// Semantic/category label for a given column
 	YLabel string `json:"y_label,omitempty"`
+	// Order of statements and listed values, one of "position" (default), "header", or "value"
+	Sort string `json:"sort,omitempty"`
+	// text/template string used by the template formatters, with named fields such as {{.XHead}}, {{.Value}}, and {{.Fields.ValLabel}}
+	Template string `json:"template,omitempty"`
+	// Labels for each level of a multi-column row header, outermost first, which take the place of x_label in named formatters
+	XLevels []string `json:"x_levels,omitempty"`
+	// Labels for each level of a multi-row column header, outermost first, which take the place of y_label in named formatters
+	YLevels []string `json:"y_levels,omitempty"`
 }
 
 // Handles user input file paths and table parsing behavior settings
 type ConfigFields struct {
 	// The number of columns that should be counted as the header for each row
-	NRowHeaders int `json:"row_headers"`
+	// When unset, headers marked up in the source document are used where the format supports it
+	NRowHeaders *int `json:"row_headers,omitempty"`
 	// The number of rows that should be counted as the header for each column
-	NColHeaders int `json:"col_headers"`
-	// File containing tabular data to read in
+	// When unset, headers marked up in the source document are used where the format supports it
+	NColHeaders *int `json:"col_headers,omitempty"`
+	// File containing tabular data to read in, or - to read from stdin
 	InFile string `json:"infile"`
-	// text file to save reformatted data
+	// text file to save reformatted data, or - to write to stdout
 	OutFile string `json:"outfile"`
 	// TableFormatter to use when reformatting tabular data
-	Formatter string `json:"formatter"`
-	// FileParser to use when reading from InFile, corresponding to file format and structure
-	Parser string `json:"parser"`
+	Formatter string `json:"formatter,omitempty"`
+	// FileParser to use when reading from InFile, corresponding to file format and structure, or auto to detect it from the file
+	Parser string `json:"parser,omitempty"`
+	// Sheet to read from multi-sheet sources, by name or 0 based index. Defaults to the first sheet
+	Sheet string `json:"sheet,omitempty"`
+	// Cell range to read from spreadsheet sources in A1 notation, such as B3:H40. Defaults to all used cells
+	Range string `json:"range,omitempty"`
+	// Table to read from multi-table HTML and MD sources, by 0 based index, caption text, or css selector. Defaults to the first table
+	// "all" formats every table in the document, prefixing each statement with the table it came from
+	Table string `json:"table,omitempty"`
+	// How HTML and MD cells covered by colspan/rowspan are filled, either "repeat" to copy the spanned value into each cell or "empty" to leave them blank
+	// Defaults to repeat
+	Spans string `json:"spans,omitempty"`
+	// If header cells should be reformatted into statements alongside data cells, as if they were values
+	IncludeHeaders bool `json:"include_headers,omitempty"`
+	// Value types for specific columns, keyed by column name or 0 based index, which take priority over inferred types
+	// One of "string", "int", "decimal", "bool", "date", "currency", or "percent"
+	Types map[string]string `json:"types,omitempty"`
+	// Dialect options for CSV and TSV sources
+	CSV CSVOptions `json:"csv"`
+	// Character encoding of text sources, such as "utf-16le" or "windows-1252"
+	// When unset, the encoding is detected from a byte order mark, falling back to windows-1252 for invalid UTF-8
+	Encoding string `json:"encoding,omitempty"`
+	// Unicode normalization applied to every cell, any of "nfc", "quotes", and "spaces"
+	Normalize []string `json:"normalize,omitempty"`
+	// Number of input files to read and reformat concurrently in batch runs, defaulting to 1
+	Jobs int `json:"jobs,omitempty"`
+	// If CSV, TSV, and JSONL sources should be read and reformatted one row at a time, keeping memory use flat for large files
+	Stream bool `json:"stream,omitempty"`
+	// If blank header cells should be filled with the header value before them, down each row header column and right along each column header row
+	// Used for grouped layouts which only label the first row or column of each group
+	FillHeaders bool `json:"fill_headers,omitempty"`
+	// How cells which are blank or hold a null value are formatted, either "keep" to format them like any other,
+	// "skip" to leave them out, or "substitute" to format them with EmptyText in place of their value. Defaults to keep
+	EmptyCells string `json:"empty_cells,omitempty"`
+	// Phrase formatted in place of empty cells when substituting, defaulting to "not available"
+	EmptyText string `json:"empty_text,omitempty"`
+	// Values treated as null alongside blank cells, ignoring case. Defaults to NA, N/A, null, -, and —
+	NullValues []string `json:"null_values,omitempty"`
+	// Reshaping applied to each table after it is read, melting wide tables into long form or pivoting long tables into wide form
+	Transform TransformOptions `json:"transform"`
+	// If rows and columns should be swapped once a table is read, for tables oriented the other way to what the formatters expect
+	// Header counts, labels, types, and other options still describe the table as read, and are swapped along with it
+	Transpose bool `json:"transpose,omitempty"`
+	// TableFormatters to use in turn when reformatting tabular data, taking the place of Formatter when set
+	// Statements from each formatter are written to the same output, in the order the formatters are listed
+	Formatters []string `json:"formatters,omitempty"`
 }
 
-// Reads config.json at specified path into ConfigFields struct
+// A single run of the pipeline declared in a config file, with its table parsing settings and formatter fields
+type RunConfig struct {
+	ConfigFields
+	FormatFields
+}
+
+// Handles dialect options for reading delimited text, shared by all delimited formats
+type CSVOptions struct {
+	// Single character separating fields, defaulting to , for CSV and tab for TSV
+	Delimiter string `json:"delimiter,omitempty"`
+	// Single character used to quote fields, defaulting to "
+	Quote string `json:"quote,omitempty"`
+	// Single character marking lines to ignore when it begins the line, such as #
+	Comment string `json:"comment,omitempty"`
+	// If quotes may appear in unquoted fields and unbalanced quotes should be read as text
+	LazyQuotes bool `json:"lazy_quotes,omitempty"`
+	// If leading white space in each field should be ignored
+	TrimLeadingSpace bool `json:"trim_leading_space,omitempty"`
+	// Number of lines to skip at the start of the file before reading the table
+	SkipLines int `json:"skip_lines,omitempty"`
+	// If rows may have differing numbers of fields, with short rows padded with empty cells
+	Ragged bool `json:"ragged,omitempty"`
+}
+
+// Returns the number of column and row headers to pass to NewTableData for a table
+// Counts set in the config take priority over those detected from the table markup
+func (c ConfigFields) HeaderCounts(t LabeledTable) (y, x int) {
+	y, x = t.col_headers, t.row_headers
+	if c.NColHeaders != nil {
+		y = *c.NColHeaders
+	}
+	if c.NRowHeaders != nil {
+		x = *c.NRowHeaders
+	}
+	return y, x
+}
+
+// Returns the names of the TableFormatters to use, in order
+func (c ConfigFields) FormatterNames() []string {
+	if len(c.Formatters) > 0 {
+		return c.Formatters
+	}
+	return []string{c.Formatter}
+}
+
+// Reads config.json at specified path into ConfigFields struct, after validating it against config.schema.json
 func ReadConfig(p string) (ConfigFields, error) {
 	var config ConfigFields
-	dec := json.NewDecoder(ReaderFromFile(p))
-	err := dec.Decode(&config)
+	content, _, err := readConfigFile(p)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(content, &config)
 	return config, err
 }
 
-// Reads config.json at specified path into FormatFields struct
+// Reads config.json at specified path into FormatFields struct, after validating it against config.schema.json
 func ReadFields(p string) (FormatFields, error) {
 	var fields FormatFields
-	dec := json.NewDecoder(ReaderFromFile(p))
-	err := dec.Decode(&fields)
+	content, _, err := readConfigFile(p)
+	if err != nil {
+		return fields, err
+	}
+	err = json.Unmarshal(content, &fields)
 	return fields, err
 }
 
+// Reads config.json at specified path and validates it against config.schema.json, returning its contents along with the decoded object
+func readConfigFile(p string) ([]byte, map[string]any, error) {
+	content, err := readFile(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	var obj map[string]any
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	err = dec.Decode(&obj)
+	if err != nil {
+		return nil, nil, jsonError(p, content, dec.InputOffset(), err)
+	}
+	err = validateConfig(p, obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, obj, nil
+}
+
+// Reads config.json at specified path into one RunConfig for each entry of its "runs" list, after validating it against config.schema.json
+// The other fields of the file are shared defaults which every run inherits and can override, with nested objects such as "csv" merged key by key
+// A file without a "runs" list is read as a single run
+func ReadRuns(p string) ([]RunConfig, error) {
+	_, shared, err := readConfigFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// The schema only allows runs to be a list of objects
+	runs := []any{map[string]any{}}
+	list, ok := shared["runs"]
+	if ok {
+		runs = list.([]any)
+		delete(shared, "runs")
+	}
+	delete(shared, "$schema")
+	out := []RunConfig{}
+	for i, r := range runs {
+		b, err := json.Marshal(mergeJSON(shared, r.(map[string]any)))
+		if err != nil {
+			return nil, err
+		}
+		var run RunConfig
+		err = json.Unmarshal(b, &run)
+		if err != nil {
+			return nil, &ConfigError{p, fmt.Sprintf("runs[%d]", i), err.Error()}
+		}
+		out = append(out, run)
+	}
+	return out, nil
+}
+
+// Overlays the fields of a run on the shared fields of a config, merging nested objects key by key
+func mergeJSON(shared, run map[string]any) map[string]any {
+	out := map[string]any{}
+	for k, v := range shared {
+		out[k] = v
+	}
+	for k, v := range run {
+		shared_obj, shared_ok := out[k].(map[string]any)
+		run_obj, run_ok := v.(map[string]any)
+		if shared_ok && run_ok {
+			out[k] = mergeJSON(shared_obj, run_obj)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Formatter fields which each TableFormatter puts into its statements, following the field table in the README
+var formatterFields = map[string][]string{
+	"UnnamedCoordFormatter1":    {"link", "eq", "val_label"},
+	"UnnamedCoordFormatter2":    {"link", "eq", "val_label"},
+	"NamedCoordFormatter1":      {"link", "eq", "val_label", "x_label", "y_label"},
+	"NamedCoordFormatter2":      {"link", "eq", "val_label", "x_label", "y_label"},
+	"NamedRowFormatter":         {"link", "eq", "x_label"},
+	"NamedColFormatter":         {"link", "eq", "y_label"},
+	"UnnamedRowKeyValFormatter": {"link", "eq"},
+	"UnnamedColKeyValFormatter": {"link", "eq"},
+	"NamedRowKeyValFormatter":   {"link", "eq", "x_label"},
+	"NamedColKeyValFormatter":   {"link", "eq", "y_label"},
+	"RowValFormatter":           {"link", "pre"},
+	"ColValFormatter":           {"link", "pre"},
+	"TemplateFormatter":         {"template"},
+	"RowTemplateFormatter":      {"template"},
+	"ColTemplateFormatter":      {"template"},
+}
+
+// Swaps the row and column labels, for formatting tables transposed after they are read
+func (f FormatFields) transposed() FormatFields {
+	f.XLabel, f.YLabel = f.YLabel, f.XLabel
+	f.XLevels, f.YLevels = f.YLevels, f.XLevels
+	return f
+}
+
+// Returns a warning for each field a run's formatters put into their statements which is left empty
+func FieldWarnings(c ConfigFields, f FormatFields) []string {
+	values := map[string]string{
+		"link":      f.Link,
+		"eq":        f.Eq,
+		"pre":       f.Pre,
+		"val_label": f.ValLabel,
+		"x_label":   f.XLabel,
+		"y_label":   f.YLabel,
+		"template":  f.Template,
+	}
+	// Level labels take the place of x_label and y_label in statements when they are set
+	covered := map[string]bool{
+		"x_label": len(f.XLevels) > 0,
+		"y_label": len(f.YLevels) > 0,
+	}
+	out := []string{}
+	for _, name := range c.FormatterNames() {
+		if name == "" {
+			name = DefaultFormatter
+		}
+		for _, field := range formatterFields[name] {
+			// Transposed tables take their row label from y_label and their column label from x_label
+			if c.Transpose && field == "x_label" {
+				field = "y_label"
+			} else if c.Transpose && field == "y_label" {
+				field = "x_label"
+			}
+			if values[field] == "" && !covered[field] {
+				out = append(out, fmt.Sprintf("%s uses %s, which is empty", name, field))
+			}
+		}
+	}
+	return out
+}
+
+// Formatter and parser used when a config leaves them empty
+const (
+	DefaultFormatter = "UnnamedCoordFormatter1"
+	DefaultParser    = "CSV"
+)
+
 // Returns a populated a TableFormatter based on the provided formatter name and TableData struct
-func SetFormatter(t TableData, f string) TableFormatter {
+// An empty name uses DefaultFormatter, and any other name that isn't a TableFormatter returns an error
+// Template formatters parse the template in FormatFields here, returning an error if it is invalid
+func SetFormatter(t TableData, f string, ff FormatFields) (TableFormatter, error) {
+	if f == "" {
+		f = DefaultFormatter
+	}
 	switch f {
 	case "UnnamedCoordFormatter1":
-		return &UnnamedCoordFormatter1{t}
+		return &UnnamedCoordFormatter1{t}, nil
 	case "UnnamedCoordFormatter2":
-		return &UnnamedCoordFormatter2{t}
+		return &UnnamedCoordFormatter2{t}, nil
 	case "NamedCoordFormatter1":
-		return &NamedCoordFormatter1{t}
+		return &NamedCoordFormatter1{t}, nil
 	case "NamedCoordFormatter2":
-		return &NamedCoordFormatter2{t}
+		return &NamedCoordFormatter2{t}, nil
 	case "NamedRowFormatter":
-		return &NamedRowFormatter{t}
+		return &NamedRowFormatter{t}, nil
 	case "NamedColFormatter":
-		return &NamedColFormatter{t}
+		return &NamedColFormatter{t}, nil
 	case "UnnamedRowKeyValFormatter":
-		return &UnnamedRowKeyValFormatter{t}
+		return &UnnamedRowKeyValFormatter{t}, nil
 	case "UnnamedColKeyValFormatter":
-		return &UnnamedColKeyValFormatter{t}
+		return &UnnamedColKeyValFormatter{t}, nil
 	case "NamedRowKeyValFormatter":
-		return &NamedRowKeyValFormatter{t}
+		return &NamedRowKeyValFormatter{t}, nil
 	case "NamedColKeyValFormatter":
-		return &NamedColKeyValFormatter{t}
+		return &NamedColKeyValFormatter{t}, nil
 	case "RowValFormatter":
-		return &RowValFormatter{t}
+		return &RowValFormatter{t}, nil
 	case "ColValFormatter":
-		return &ColValFormatter{t}
+		return &ColValFormatter{t}, nil
+	case "TemplateFormatter", "RowTemplateFormatter", "ColTemplateFormatter":
+		tmpl, err := parseTemplate(ff.Template)
+		if err != nil {
+			return nil, err
+		}
+		switch f {
+		case "RowTemplateFormatter":
+			return &RowTemplateFormatter{t, tmpl}, nil
+		case "ColTemplateFormatter":
+			return &ColTemplateFormatter{t, tmpl}, nil
+		}
+		return &TemplateFormatter{t, tmpl}, nil
 	default:
-		fmt.Println("Invalid formatter provided, defaulting to UnnamedCoordFormatter1")
-		return &UnnamedCoordFormatter1{t}
+		return nil, fmt.Errorf("unknown formatter %q", f)
 	}
 }
 
-// Returns a populated FileParser based on the provided parser name and file path
-func SetParser(p string, f string) FileParser {
-	switch f {
+// Returns a populated FileParser based on the parser name, file path, and parser options in ConfigFields
+// An empty name uses DefaultParser, and any other name that isn't a FileParser returns an error
+func SetParser(c ConfigFields) (FileParser, error) {
+	p := c.InFile
+	if c.Parser == "" {
+		c.Parser = DefaultParser
+	}
+	switch c.Parser {
 	case "CSV":
-		return &CSVParser{p}
+		return &CSVParser{p, c.CSV, c.Encoding}, nil
 	case "TSV":
-		return &TSVParser{p}
+		return &TSVParser{p, c.CSV, c.Encoding}, nil
 	case "JSONLines":
-		return &JSONLinesParser{p}
+		return &JSONLinesParser{p, c.Encoding}, nil
 	case "JSONArrObj":
-		return &JSONArrObjParser{p}
+		return &JSONArrObjParser{p, c.Encoding}, nil
 	case "JSONArrArr":
-		return &JSONArrArrParser{p}
+		return &JSONArrArrParser{p, c.Encoding}, nil
 	case "MD":
-		return &MDParser{p}
+		return &MDParser{p, c.Table, c.Spans, c.Encoding}, nil
 	case "HTML":
-		return &HTMLParser{p}
+		return &HTMLParser{p, c.Table, c.Spans, c.Encoding}, nil
+	case "XLSX":
+		return &XLSXParser{p, c.Sheet, c.Range}, nil
+	case "ODS":
+		return &ODSParser{p, c.Sheet, c.Range}, nil
 	default:
-		fmt.Println("Invalid parser provided, defaulting to CSVParser")
-		return &CSVParser{p}
+		return nil, fmt.Errorf("unknown parser %q", c.Parser)
 	}
 }
@@ -5,15 +5,28 @@
 
 package main
 
-import "testing"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func ref_int(i int) *int {
+	return &i
+}
 
 func TestReadConfig(t *testing.T) {
 	table := [2]struct {
 		path string
 		exp  ConfigFields
 	}{
-		{"data/test_config1.json", ConfigFields{0, 0, "", "", "", ""}},
-		{"data/test_config2.json", ConfigFields{10, 1000, "test.csv", "test.txt", "test", "test"}},
+		{"data/test_config1.json", ConfigFields{ref_int(0), ref_int(0), "", "", "", "", "", "", "", "", false, nil, CSVOptions{}, "", nil, 0, false, false, "", "", nil, TransformOptions{}, false, nil}},
+		{"data/test_config2.json", ConfigFields{ref_int(10), ref_int(1000), "test.csv", "test.txt", "NamedRowFormatter", "CSV", "test", "test", "test", "repeat", true, map[string]string{"test": "string"}, CSVOptions{"test", "test", "test", true, true, 10, true}, "test", []string{"nfc"}, 10, true, true, "substitute", "test", []string{"test"}, TransformOptions{"melt", []string{"test"}, "test", "test"}, true, []string{"NamedRowFormatter"}}},
 	}
 
 	for _, test := range table {
@@ -21,7 +34,7 @@ func TestReadConfig(t *testing.T) {
 		if err != nil {
 			t.Errorf("%v", err)
 		}
-		if res != test.exp {
+		if !reflect.DeepEqual(res, test.exp) {
 			t.Errorf("ReadConfig(%v) = %v, expected %v", test.path, res, test.exp)
 		}
 	}
@@ -32,8 +45,8 @@ func TestReadFields(t *testing.T) {
 		path string
 		exp  FormatFields
 	}{
-		{"data/test_config1.json", FormatFields{"", "", "", "", "", "", ""}},
-		{"data/test_config2.json", FormatFields{"test", "test", "test", "test", "test", "test", "test"}},
+		{"data/test_config1.json", FormatFields{"", "", "", "", "", "", "", "", "", nil, nil}},
+		{"data/test_config2.json", FormatFields{"test", "test", "test", "test", "test", "test", "test", "value", "test", []string{"test"}, []string{"test"}}},
 	}
 
 	for _, test := range table {
@@ -41,8 +54,246 @@ func TestReadFields(t *testing.T) {
 		if err != nil {
 			t.Errorf("%v", err)
 		}
-		if res != test.exp {
+		if !reflect.DeepEqual(res, test.exp) {
 			t.Errorf("ReadFields(%v) = %v, expected %v", test.path, res, test.exp)
 		}
 	}
 }
+
+func TestReadConfigErrors(t *testing.T) {
+	table := []struct {
+		content string
+		exp     string
+	}{
+		{`{"val-label": "price"}`, `unknown key "val-label", did you mean "val_label"?`},
+		{`{"formatter": "NamedCordFormatter1"}`, `at formatter: unknown value "NamedCordFormatter1", did you mean "NamedCoordFormatter1"?`},
+		{`{"row_headers": "1"}`, `at row_headers: expected a whole number, got string "1"`},
+	}
+
+	for _, test := range table {
+		p := filepath.Join(t.TempDir(), "config.json")
+		err := os.WriteFile(p, []byte(test.content), 0o644)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		_, err = ReadConfig(p)
+		if err == nil || !strings.Contains(err.Error(), test.exp) {
+			t.Errorf("ReadConfig(%v) returned error %v, expected %q", test.content, err, test.exp)
+		}
+		_, err = ReadFields(p)
+		if err == nil || !strings.Contains(err.Error(), test.exp) {
+			t.Errorf("ReadFields(%v) returned error %v, expected %q", test.content, err, test.exp)
+		}
+	}
+}
+
+func TestReadRuns(t *testing.T) {
+	shared := FormatFields{Link: "for", Eq: "is", ValLabel: "price"}
+	table := []struct {
+		path string
+		exp  []RunConfig
+	}{
+		{"data/test_config3.json", []RunConfig{
+			{ConfigFields{NRowHeaders: ref_int(1), NColHeaders: ref_int(1), InFile: "data/test1.csv", OutFile: "outputs/test_config3.txt", Formatter: "UnnamedCoordFormatter1", Parser: "CSV"}, shared},
+		}},
+		{"data/test_config5.json", []RunConfig{
+			{ConfigFields{NRowHeaders: ref_int(1), NColHeaders: ref_int(1), InFile: "data/test1.csv", OutFile: "outputs/test_config5_coord.txt", Formatter: "UnnamedCoordFormatter1", Parser: "CSV", CSV: CSVOptions{Delimiter: ",", TrimLeadingSpace: true}}, shared},
+			{ConfigFields{NRowHeaders: ref_int(1), NColHeaders: ref_int(1), InFile: "data/test1.csv", OutFile: "outputs/test_config5_row.txt", Parser: "CSV", CSV: CSVOptions{Delimiter: ",", LazyQuotes: true, TrimLeadingSpace: true}, Formatters: []string{"NamedRowKeyValFormatter", "NamedRowFormatter"}}, FormatFields{Link: "when", Eq: "is", ValLabel: "price", XLabel: "row"}},
+			{ConfigFields{NRowHeaders: ref_int(0), NColHeaders: ref_int(1), InFile: "data/test1.tsv", OutFile: "outputs/test_config5_tsv.txt", Formatter: "UnnamedCoordFormatter1", Parser: "TSV", CSV: CSVOptions{Delimiter: "\t", TrimLeadingSpace: true}}, shared},
+		}},
+	}
+
+	for _, test := range table {
+		res, err := ReadRuns(test.path)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if !reflect.DeepEqual(res, test.exp) {
+			t.Errorf("ReadRuns(%v) = %v, expected %v", test.path, res, test.exp)
+		}
+	}
+}
+
+func TestReadRunsErrors(t *testing.T) {
+	table := []struct {
+		content string
+		exp     string
+	}{
+		{`{"runs": []}`, "at runs: expected 1 or more items"},
+		{`{"runs": {"infile": "test.csv"}}`, "at runs: expected a list, got an object"},
+		{`{"runs": ["test.csv"]}`, `at runs[0]: expected an object, got string "test.csv"`},
+		{`{"runs": [{}, {"row_headers": "test"}]}`, `at runs[1].row_headers: expected a whole number, got string "test"`},
+		{`{"runs": [{"row_headers": -1}]}`, `at runs[0].row_headers: expected a number of at least 0, got -1`},
+		{`{"runs": [{}]`, "malformed JSON"},
+		{`{"val-label": "price"}`, `unknown key "val-label", did you mean "val_label"?`},
+		{`{"runs": [{"csv": {"delimeter": ";"}}]}`, `at runs[0].csv: unknown key "delimeter", did you mean "delimiter"?`},
+		{`{"formatter": "NamedCordFormatter1"}`, `at formatter: unknown value "NamedCordFormatter1", did you mean "NamedCoordFormatter1"?`},
+		{`{"parser": "SQL"}`, `at parser: unknown value "SQL", expected one of auto, CSV`},
+		{`{"types": {"price": "money"}}`, `at types.price: unknown value "money"`},
+		{`{"stream": "yes"}`, `at stream: expected true or false, got string "yes"`},
+		{`{"colour": "red"}`, `unknown key "colour"`},
+	}
+
+	for _, test := range table {
+		p := filepath.Join(t.TempDir(), "config.json")
+		err := os.WriteFile(p, []byte(test.content), 0o644)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		_, err = ReadRuns(p)
+		if err == nil || !strings.Contains(err.Error(), test.exp) {
+			t.Errorf("ReadRuns(%v) returned error %v, expected %q", test.content, err, test.exp)
+		}
+	}
+}
+
+func TestCheckFlagValue(t *testing.T) {
+	table := []struct {
+		flag  string
+		value string
+		exp   string
+	}{
+		{"formatter", "", ""},
+		{"formatter", "NamedCoordFormatter1", ""},
+		{"formatter", "NamedCordFormatter1", `unknown value "NamedCordFormatter1" for --formatter, did you mean "NamedCoordFormatter1"?`},
+		{"parser", "auto", ""},
+		{"parser", "CVS", `unknown value "CVS" for --parser, did you mean "CSV"?`},
+		{"parser", "spreadsheet", `unknown value "spreadsheet" for --parser, expected one of auto, CSV, TSV, JSONLines, JSONArrObj, JSONArrArr, MD, HTML, XLSX, ODS`},
+	}
+
+	for _, test := range table {
+		err := checkFlagValue(test.flag, test.value, test.flag)
+		if fmt.Sprint(err) != fmt.Sprint(test.exp) && !(err == nil && test.exp == "") {
+			t.Errorf("checkFlagValue(%v, %v) = %v, expected %v", test.flag, test.value, err, test.exp)
+		}
+	}
+
+	for _, name := range []string{"Coord", "test"} {
+		_, err := SetFormatter(TableData{}, name, FormatFields{})
+		if err == nil {
+			t.Errorf("SetFormatter(%v) returned no error", name)
+		}
+		_, err = SetParser(ConfigFields{Parser: name})
+		if err == nil {
+			t.Errorf("SetParser(%v) returned no error", name)
+		}
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	var schema jsonSchema
+	err := json.Unmarshal(configSchemaJSON, &schema)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	json_keys := func(v any) []string {
+		out := []string{}
+		for _, f := range reflect.VisibleFields(reflect.TypeOf(v)) {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name != "" {
+				out = append(out, name)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	schema_keys := func(s *jsonSchema) []string {
+		out := []string{}
+		for k := range s.Properties {
+			out = append(out, k)
+		}
+		sort.Strings(out)
+		return out
+	}
+	table := []struct {
+		schema *jsonSchema
+		exp    []string
+	}{
+		{schema.Defs["run"], json_keys(RunConfig{})},
+		{schema.Defs["csv"], json_keys(CSVOptions{})},
+	}
+
+	for _, test := range table {
+		res := schema_keys(test.schema)
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("config schema lists keys %v, expected %v", res, test.exp)
+		}
+	}
+	res := schema_keys(&schema)
+	exp := append(json_keys(RunConfig{}), "$schema", "runs")
+	sort.Strings(exp)
+	if fmt.Sprint(res) != fmt.Sprint(exp) {
+		t.Errorf("config schema lists keys %v, expected %v", res, exp)
+	}
+	for _, name := range schema.Defs["formatter"].Enum {
+		_, ok := formatterFields[name]
+		if !ok && name != "" {
+			t.Errorf("formatter %v has no entry in formatterFields", name)
+		}
+		_, err := SetFormatter(TableData{}, name, FormatFields{})
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+	for _, name := range schema.Defs["parser"].Enum {
+		_, err := SetParser(ConfigFields{Parser: name})
+		if err != nil && name != ParserAuto {
+			t.Errorf("%v", err)
+		}
+	}
+
+	for _, p := range []string{"config.json", "lastrun.json", "data/test_config1.json", "data/test_config3.json", "data/test_config4.json", "data/test_config5.json"} {
+		_, err := ReadRuns(p)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+}
+
+func TestFieldWarnings(t *testing.T) {
+	table := []struct {
+		config ConfigFields
+		fields FormatFields
+		exp    []string
+	}{
+		{ConfigFields{Formatter: "UnnamedCoordFormatter1"}, FormatFields{Link: "for", Eq: "is", ValLabel: "price"}, []string{}},
+		{ConfigFields{Formatter: "NamedCoordFormatter1"}, FormatFields{Link: "for", Eq: "is", ValLabel: "price", YLabel: "crust"}, []string{"NamedCoordFormatter1 uses x_label, which is empty"}},
+		{ConfigFields{Formatters: []string{"RowValFormatter", "TemplateFormatter"}}, FormatFields{Link: "are"}, []string{"RowValFormatter uses pre, which is empty", "TemplateFormatter uses template, which is empty"}},
+		{ConfigFields{Formatter: "test"}, FormatFields{}, []string{}},
+		{ConfigFields{Formatter: "NamedRowKeyValFormatter", Transpose: true}, FormatFields{Link: "for", Eq: "is", XLabel: "attribute"}, []string{"NamedRowKeyValFormatter uses y_label, which is empty"}},
+		{ConfigFields{Formatter: "NamedRowKeyValFormatter", Transpose: true}, FormatFields{Link: "for", Eq: "is", YLabel: "product"}, []string{}},
+		{ConfigFields{Formatter: "NamedCoordFormatter1"}, FormatFields{Link: "for", Eq: "is", ValLabel: "sales", XLabel: "region", YLevels: []string{"year", "quarter"}}, []string{}},
+		{ConfigFields{Formatter: "NamedCoordFormatter1"}, FormatFields{Link: "for", Eq: "is", ValLabel: "sales", XLevels: []string{"region"}}, []string{"NamedCoordFormatter1 uses y_label, which is empty"}},
+		{ConfigFields{Formatter: "NamedRowFormatter", Transpose: true}, FormatFields{Link: "for", Eq: "is", YLevels: []string{"product"}}, []string{}},
+	}
+
+	for _, test := range table {
+		res := FieldWarnings(test.config, test.fields)
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("FieldWarnings(%v, %v) = %v, expected %v", test.config.FormatterNames(), test.fields, res, test.exp)
+		}
+	}
+}
+
+func TestHeaderCounts(t *testing.T) {
+	df, _, _ := reference_dataframes()
+	table := []struct {
+		config ConfigFields
+		table  LabeledTable
+		exp_y  int
+		exp_x  int
+	}{
+		{ConfigFields{}, LabeledTable{"", df, nil, 0, 0}, 0, 0},
+		{ConfigFields{}, LabeledTable{"", df, nil, 1, 2}, 2, 1},
+		{ConfigFields{NRowHeaders: ref_int(0)}, LabeledTable{"", df, nil, 1, 2}, 2, 0},
+		{ConfigFields{NColHeaders: ref_int(3)}, LabeledTable{"", df, nil, 1, 2}, 3, 1},
+		{ConfigFields{NRowHeaders: ref_int(2), NColHeaders: ref_int(1)}, LabeledTable{"", df, nil, 0, 0}, 1, 2},
+	}
+
+	for _, test := range table {
+		y, x := test.config.HeaderCounts(test.table)
+		if y != test.exp_y || x != test.exp_x {
+			t.Errorf("HeaderCounts(%v) = %v, %v, expected %v, %v", test.table.label, y, x, test.exp_y, test.exp_x)
+		}
+	}
+}
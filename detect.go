@@ -0,0 +1,229 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 06:48:31 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Parser mode which picks the parser from the input file extension and contents
+const ParserAuto = "auto"
+
+// Parsers suggested by common file extensions
+var extensionParsers = map[string]string{
+	".csv":      "CSV",
+	".tsv":      "TSV",
+	".tab":      "TSV",
+	".jsonl":    "JSONLines",
+	".ndjson":   "JSONLines",
+	".json":     "JSONArrObj",
+	".md":       "MD",
+	".markdown": "MD",
+	".html":     "HTML",
+	".htm":      "HTML",
+	".xlsx":     "XLSX",
+	".ods":      "ODS",
+}
+
+// Reports if files with an extension are read by a parser, or by any parser for ParserAuto
+// .json files are read by both JSON parsers, and an empty name uses DefaultParser
+func parserReadsExtension(parser string, ext string) bool {
+	if parser == "" {
+		parser = DefaultParser
+	}
+	found, ok := extensionParsers[strings.ToLower(ext)]
+	switch {
+	case !ok:
+		return false
+	case parser == ParserAuto:
+		return true
+	case found == "JSONArrObj":
+		return parser == "JSONArrObj" || parser == "JSONArrArr"
+	}
+	return found == parser
+}
+
+// Delimiter row separating the header from the body of a markdown table, such as |---|:---:| or |-|-|
+var mdDelimiterRow = regexp.MustCompile(`(?m)^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)+\|?\s*$`)
+
+// Opening tags which mark content as html, when the content starts with a tag
+var htmlTag = regexp.MustCompile(`(?i)<(!doctype html|html|table|body)[\s>]`)
+
+// Delimiters tried when sniffing delimited text, mapped to the parser which reads them
+var delimiterParsers = map[rune]string{
+	',':  "CSV",
+	'\t': "TSV",
+	';':  "CSV",
+	'|':  "CSV",
+}
+
+// Picks the parser for a file, first from its extension and then by sniffing its contents, transcoded from the specified encoding
+// Recognizable contents take precedence over the extension, so html saved as .txt is read as HTML
+// Content is only sniffed as html when it starts with a tag, so a delimited file with a cell like "see <table> docs" keeps its extension
+func DetectParser(p string, enc string) (string, error) {
+	content, err := readPrefix(p, sniffSize)
+	if err != nil {
+		return "", err
+	}
+	ext := extensionParsers[strings.ToLower(filepath.Ext(p))]
+	sniffed := ""
+	if bytes.HasPrefix(content, zipMagic) {
+		// The zip directory is at the end of the file, so workbooks are read in full
+		content, err = readFile(p)
+		if err != nil {
+			return "", err
+		}
+		sniffed = sniffWorkbook(content)
+	} else {
+		content, err = sniffText(content, enc)
+		if err != nil {
+			return "", err
+		}
+		sniffed = sniffParser(content)
+	}
+	switch {
+	case sniffed == "":
+	case ext == "MD" && sniffed == "HTML":
+		// Markdown can contain inline html tables, which MDParser reads as well
+	default:
+		return sniffed, nil
+	}
+	if ext == "" {
+		return "CSV", nil
+	}
+	return ext, nil
+}
+
+// Leading bytes of a zip container
+var zipMagic = []byte("PK\x03\x04")
+
+// Transcodes the start of a text file to UTF-8 for sniffing, dropping the last line if the prefix cut it off
+func sniffText(prefix []byte, enc string) ([]byte, error) {
+	if len(prefix) == sniffSize {
+		if i := bytes.LastIndexByte(prefix, '\n'); i > 0 {
+			prefix = prefix[:i+1]
+		}
+	}
+	return decodeText(prefix, enc)
+}
+
+// Identifies the format of text file contents, returning an empty string if no format could be recognized
+func sniffParser(content []byte) string {
+	text := bytes.TrimSpace(content)
+	switch {
+	case len(text) == 0:
+		return ""
+	case text[0] == '[':
+		return sniffJSONArray(text)
+	case text[0] == '{':
+		return "JSONLines"
+	case text[0] == '<' && htmlTag.Match(text):
+		return "HTML"
+	case mdDelimiterRow.Match(text):
+		return "MD"
+	}
+	return delimiterParsers[detectDelimiter(text, 0)]
+}
+
+// Tells xlsx and ods workbooks apart from the files inside the zip container, returning an empty string for other contents
+func sniffWorkbook(content []byte) string {
+	if !bytes.HasPrefix(content, zipMagic) {
+		return ""
+	}
+	r, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return ""
+	}
+	mimetype, err := readZipFile(r, "mimetype")
+	if err == nil && strings.Contains(string(mimetype), "opendocument.spreadsheet") {
+		return "ODS"
+	}
+	_, err = readZipFile(r, "xl/workbook.xml")
+	if err == nil {
+		return "XLSX"
+	}
+	return ""
+}
+
+// Tells array of arrays JSON from array of objects JSON by the first element of the array
+func sniffJSONArray(text []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(text))
+	_, err := dec.Token()
+	if err != nil {
+		return ""
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return ""
+	}
+	if tok == json.Delim('[') {
+		return "JSONArrArr"
+	}
+	return "JSONArrObj"
+}
+
+// Detects the delimiter of a delimited text file in the specified encoding, after skipping leading and comment lines according to the dialect options
+// Returns an empty string if no delimiter could be detected
+func DetectDelimiter(p string, enc string, o CSVOptions) (string, error) {
+	content, err := readPrefix(p, sniffSize)
+	if err != nil {
+		return "", err
+	}
+	content, err = sniffText(content, enc)
+	if err != nil {
+		return "", err
+	}
+	comment, err := dialectRune("comment", o.Comment, 0)
+	if err != nil {
+		return "", err
+	}
+	text := string(content)
+	for range o.SkipLines {
+		_, text, _ = strings.Cut(text, "\n")
+	}
+	d := detectDelimiter([]byte(text), comment)
+	if d == 0 {
+		return "", nil
+	}
+	return string(d), nil
+}
+
+// Picks the delimiter which splits the first lines of text into the most fields, consistently across every line
+// Returns 0 if no delimiter splits the lines consistently
+func detectDelimiter(text []byte, comment rune) rune {
+	best, best_fields := rune(0), 1
+	for _, d := range []rune{',', '\t', ';', '|'} {
+		r := csv.NewReader(bytes.NewReader(text))
+		r.Comma = d
+		r.Comment = comment
+		r.LazyQuotes = true
+		r.FieldsPerRecord = -1
+		fields := 0
+		for range 10 {
+			record, err := r.Read()
+			if err != nil {
+				break
+			}
+			if fields == 0 {
+				fields = len(record)
+			}
+			if len(record) != fields {
+				fields = 0
+				break
+			}
+		}
+		if fields > best_fields {
+			best, best_fields = d, fields
+		}
+	}
+	return best
+}
@@ -0,0 +1,92 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 06:48:31 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectParser(t *testing.T) {
+	table := []struct {
+		path string
+		exp  string
+	}{
+		{"data/test1.csv", "CSV"},
+		{"data/test1.tsv", "TSV"},
+		{"data/test1.jsonl", "JSONLines"},
+		{"data/test_arr_obj1.json", "JSONArrObj"},
+		{"data/test_arr_arr1.json", "JSONArrArr"},
+		{"data/test1.md", "MD"},
+		{"data/test4.md", "MD"},
+		{"data/test_md_short.md", "MD"},
+		{"data/test1.html", "HTML"},
+		{"data/test1.xlsx", "XLSX"},
+		{"data/test1.ods", "ODS"},
+		{"data/test7.txt", "HTML"},
+		{"data/test8.csv", "TSV"},
+		{"data/test_html_cell.csv", "CSV"},
+	}
+
+	for _, test := range table {
+		res, err := DetectParser(test.path, "")
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if res != test.exp {
+			t.Errorf("DetectParser(%v) = %v, expected %v", test.path, res, test.exp)
+		}
+	}
+
+	var not_found *FileNotFoundError
+	_, err := DetectParser("data/missing.csv", "")
+	if !errors.As(err, &not_found) {
+		t.Errorf("DetectParser(data/missing.csv) returned error %v, expected %T", err, not_found)
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	table := []struct {
+		text string
+		exp  rune
+	}{
+		{"a,b,c\n1,2,3", ','},
+		{"a\tb\tc\n1\t2\t3", '\t'},
+		{"a;b;c\n1;2;3", ';'},
+		{"a|b\n1|2", '|'},
+		{"\"a,1\";b;c\n\"x,y\";2;3", ';'},
+		{"a,b\n1,2,3", 0},
+		{"a\nb", 0},
+	}
+
+	for _, test := range table {
+		res := detectDelimiter([]byte(test.text), 0)
+		if res != test.exp {
+			t.Errorf("detectDelimiter(%q) = %q, expected %q", test.text, res, test.exp)
+		}
+	}
+
+	file_table := []struct {
+		path string
+		opts CSVOptions
+		exp  string
+	}{
+		{"data/test1.csv", CSVOptions{}, ","},
+		{"data/test_pipe.csv", CSVOptions{}, "|"},
+		{"data/test_semicolon.csv", CSVOptions{Comment: "#", SkipLines: 2}, ";"},
+		{"data/test_semicolon.csv", CSVOptions{}, ""},
+	}
+
+	for _, test := range file_table {
+		res, err := DetectDelimiter(test.path, "", test.opts)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if res != test.exp {
+			t.Errorf("DetectDelimiter(%v, %v) = %q, expected %q", test.path, test.opts, res, test.exp)
+		}
+	}
+}
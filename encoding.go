@@ -0,0 +1,203 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 07:35:06 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization modes for cell values
+const (
+	// Composes characters into Unicode NFC form, so visually identical values compare equal
+	NormalizeNFC = "nfc"
+	// Replaces curly single and double quotes with their straight ascii forms
+	NormalizeQuotes = "quotes"
+	// Replaces non-breaking and other fixed width spaces with regular spaces
+	NormalizeSpaces = "spaces"
+)
+
+// Byte order marks and the encodings they identify
+var byteOrderMarks = []struct {
+	bom []byte
+	enc encoding.Encoding
+}{
+	{[]byte{0xef, 0xbb, 0xbf}, unicode.UTF8BOM},
+	{[]byte{0xff, 0xfe}, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)},
+	{[]byte{0xfe, 0xff}, unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)},
+}
+
+// Replacements made for the quotes and spaces normalization modes
+var (
+	quoteReplacer = strings.NewReplacer("‘", "'", "’", "'", "‚", "'", "‛", "'", "“", `"`, "”", `"`, "„", `"`, "‟", `"`)
+	spaceReplacer = strings.NewReplacer(" ", " ", " ", " ", " ", " ")
+)
+
+// Transcodes file contents to UTF-8
+// A byte order mark takes priority over the named encoding, and contents with neither are read as UTF-8,
+// falling back to Windows-1252 when they aren't valid UTF-8
+func decodeText(content []byte, enc string) ([]byte, error) {
+	for _, b := range byteOrderMarks {
+		if bytes.HasPrefix(content, b.bom) {
+			return b.enc.NewDecoder().Bytes(content)
+		}
+	}
+	if enc != "" {
+		e, err := htmlindex.Get(enc)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported encoding %q", enc)
+		}
+		return e.NewDecoder().Bytes(content)
+	}
+	if utf8.Valid(content) {
+		return content, nil
+	}
+	return charmap.Windows1252.NewDecoder().Bytes(content)
+}
+
+// Reads in the contents of a text file or stdin and transcodes them to UTF-8
+func readText(p string, enc string) ([]byte, error) {
+	content, err := readFile(p)
+	if err != nil {
+		return nil, err
+	}
+	content, err = decodeText(content, enc)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", p, err)
+	}
+	return content, nil
+}
+
+// Number of bytes read from the start of a file to detect its encoding or format
+const sniffSize = 64 * 1024
+
+// Picks the encoding used to decode text from its first bytes, following the same rules as decodeText
+// Returns nil when the text can be read as is
+// Only the start of the text is checked, so a prefix cut off mid-character still counts as valid UTF-8 when truncated is set
+func sniffEncoding(head []byte, enc string, truncated bool) (encoding.Encoding, error) {
+	for _, b := range byteOrderMarks {
+		if bytes.HasPrefix(head, b.bom) {
+			return b.enc, nil
+		}
+	}
+	if enc != "" {
+		e, err := htmlindex.Get(enc)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported encoding %q", enc)
+		}
+		return e, nil
+	}
+	for i := 0; i <= utf8.UTFMax-1 && i <= len(head); i++ {
+		if utf8.Valid(head[:len(head)-i]) {
+			return nil, nil
+		}
+		if !truncated {
+			break
+		}
+	}
+	return charmap.Windows1252, nil
+}
+
+// Opens a text file or stdin as a stream transcoded to UTF-8, without reading it all into memory
+// The encoding is picked from the start of the stream, so a file that is only invalid UTF-8 further in isn't read as Windows-1252
+func openText(p string, enc string) (io.ReadCloser, error) {
+	var f io.ReadCloser
+	switch {
+	case p == StdStream:
+		f = io.NopCloser(stdin.reader())
+	default:
+		file, err := os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &FileNotFoundError{p, err}
+		}
+		if err != nil {
+			return nil, err
+		}
+		f = file
+	}
+
+	r := bufio.NewReaderSize(f, sniffSize)
+	head, err := r.Peek(sniffSize)
+	e, err := sniffEncoding(head, enc, err == nil)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if e == nil {
+		return struct {
+			io.Reader
+			io.Closer
+		}{r, f}, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{transform.NewReader(r, e.NewDecoder()), f}, nil
+}
+
+// Reads in up to n bytes from the start of a file or stdin for sniffing its format, leaving the rest of stdin unread
+func readPrefix(p string, n int) ([]byte, error) {
+	if p == StdStream {
+		return stdin.prefix(n)
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &FileNotFoundError{p, err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, int64(n)))
+}
+
+// Applies each normalization mode in turn to a cell value
+func normalizeText(s string, modes []string) (string, error) {
+	for _, m := range modes {
+		switch m {
+		case NormalizeNFC:
+			s = norm.NFC.String(s)
+		case NormalizeQuotes:
+			s = quoteReplacer.Replace(s)
+		case NormalizeSpaces:
+			s = spaceReplacer.Replace(s)
+		default:
+			return "", fmt.Errorf("unknown normalization mode %q", m)
+		}
+	}
+	return s, nil
+}
+
+// Normalizes every cell of a table, including its header row
+func normalizeTable(t LabeledTable, modes []string) (LabeledTable, error) {
+	records := t.records()
+	for _, record := range records {
+		for i := range record {
+			s, err := normalizeText(record[i], modes)
+			if err != nil {
+				return t, err
+			}
+			record[i] = s
+		}
+	}
+	normalized, err := loadTable(records)
+	t.df, t.header = normalized.df, normalized.header
+	return t, err
+}
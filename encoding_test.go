@@ -0,0 +1,108 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 07:35:06 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+func TestDecodeText(t *testing.T) {
+	table := []struct {
+		content []byte
+		enc     string
+		exp     string
+		err     bool
+	}{
+		{[]byte("café"), "", "café", false},
+		{[]byte("\xef\xbb\xbfcafé"), "", "café", false},
+		{[]byte("\xff\xfec\x00a\x00f\x00\xe9\x00"), "", "café", false},
+		{[]byte("\xfe\xff\x00c\x00a\x00f\x00\xe9"), "windows-1252", "café", false},
+		{[]byte("caf\xe9"), "", "café", false},
+		{[]byte("caf\xe9"), "iso-8859-1", "café", false},
+		{[]byte("c\x00a\x00f\x00\xe9\x00"), "utf-16le", "café", false},
+		{[]byte("café"), "ebcdic-37-ish", "", true},
+	}
+
+	for _, test := range table {
+		res, err := decodeText(test.content, test.enc)
+		if (err != nil) != test.err {
+			t.Errorf("decodeText(%q, %v) returned error %v, expected error %v", test.content, test.enc, err, test.err)
+		}
+		if string(res) != test.exp {
+			t.Errorf("decodeText(%q, %v) = %q, expected %q", test.content, test.enc, res, test.exp)
+		}
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	table := []struct {
+		s     string
+		modes []string
+		exp   string
+	}{
+		{"café", []string{}, "café"},
+		{"café", []string{NormalizeNFC}, "café"},
+		{"“it’s”", []string{NormalizeQuotes}, `"it's"`},
+		{"1 000 kg", []string{NormalizeSpaces}, "1 000 kg"},
+		{"“café” ", []string{NormalizeNFC, NormalizeQuotes, NormalizeSpaces}, `"café" `},
+	}
+
+	for _, test := range table {
+		res, err := normalizeText(test.s, test.modes)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if res != test.exp {
+			t.Errorf("normalizeText(%q, %v) = %q, expected %q", test.s, test.modes, res, test.exp)
+		}
+	}
+
+	_, err := normalizeText("", []string{"ascii"})
+	if err == nil {
+		t.Errorf("normalizeText with unknown mode returned no error")
+	}
+}
+
+func TestReadTablesEncoding(t *testing.T) {
+	load := func(records [][]string) dataframe.DataFrame {
+		return dataframe.LoadRecords(records, dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+	}
+	table := []struct {
+		config ConfigFields
+		exp    dataframe.DataFrame
+	}{
+		{ConfigFields{InFile: "data/test_utf16.csv", Parser: "CSV"}, load([][]string{
+			{"_", "col1", "col2", "col3"},
+			{"row1", "café", "val12", "val13"},
+			{"row2", "val21", "naïve", "val23"},
+		})},
+		{ConfigFields{InFile: "data/test_cp1252.csv", Parser: "CSV"}, load([][]string{
+			{"_", "col1", "col2", "col3"},
+			{"row1", "café", "“quoted”", "val13"},
+			{"row2", "val21", "1 000", "val23"},
+		})},
+		{ConfigFields{InFile: "data/test_cp1252.csv", Parser: "CSV", Encoding: "windows-1252", Normalize: []string{NormalizeQuotes, NormalizeSpaces}}, load([][]string{
+			{"_", "col1", "col2", "col3"},
+			{"row1", "café", `"quoted"`, "val13"},
+			{"row2", "val21", "1 000", "val23"},
+		})},
+	}
+
+	for _, test := range table {
+		res, err := ReadTables(test.config)
+		if err != nil {
+			t.Errorf("%v", err)
+			continue
+		}
+		if fmt.Sprint(res[0].df.Records()) != fmt.Sprint(test.exp.Records()) {
+			t.Errorf("ReadTables(%v) = %v, expected %v", test.config.InFile, res[0].df, test.exp)
+		}
+	}
+}
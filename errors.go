@@ -0,0 +1,84 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 06:02:14 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Returned when an input file does not exist, wrapping the error from opening it
+type FileNotFoundError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileNotFoundError) Error() string {
+	return fmt.Sprintf("file not found: %s", e.Path)
+}
+
+func (e *FileNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// Returned when a JSON or JSONL input file can't be decoded, with the 1 based line the problem was found on
+type MalformedJSONError struct {
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *MalformedJSONError) Error() string {
+	return fmt.Sprintf("malformed JSON in %s at line %d: %v", e.Path, e.Line, e.Err)
+}
+
+func (e *MalformedJSONError) Unwrap() error {
+	return e.Err
+}
+
+// Returned when a document contains no table matching the table selection
+type NoTableError struct {
+	Path      string
+	Selection string
+}
+
+func (e *NoTableError) Error() string {
+	if e.Selection == "" {
+		return fmt.Sprintf("no table found in %s", e.Path)
+	}
+	return fmt.Sprintf("no table found in %s matching %q", e.Path, e.Selection)
+}
+
+// Returned when a config file doesn't match the config schema, with the location of the problem such as runs[1].csv
+type ConfigError struct {
+	Path     string
+	Location string
+	Msg      string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Location == "" {
+		return fmt.Sprintf("invalid config %s: %s", e.Path, e.Msg)
+	}
+	return fmt.Sprintf("invalid config %s at %s: %s", e.Path, e.Location, e.Msg)
+}
+
+// Wraps a JSON decoding error with the line it occurred on, using the byte offset reported by the decoder
+// Offset is used for errors which don't report their own, such as an unexpected end of input
+func jsonError(p string, content []byte, offset int64, err error) error {
+	var syntax *json.SyntaxError
+	var unmarshal *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		offset = syntax.Offset
+	case errors.As(err, &unmarshal):
+		offset = unmarshal.Offset
+	}
+	offset = min(max(offset, 0), int64(len(content)))
+	return &MalformedJSONError{p, bytes.Count(content[:offset], []byte("\n")) + 1, err}
+}
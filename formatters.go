@@ -6,22 +6,166 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
+	"unicode"
 )
 
 type TableFormatter interface {
-	format(f FormatFields) []string
+	format(f FormatFields) ([]string, error)
+}
+
+// Sort modes for the order of statements and of the values listed within them
+const (
+	// Keeps the order of cells in the table, reading across each row from the top
+	SortPosition = "position"
+	// Sorts by row header, then column header
+	SortHeader = "header"
+	// Sorts by cell value, comparing numbers and dates by their typed value
+	SortValue = "value"
+)
+
+// Returns a typed value as a float64 when it's numeric
+func numericValue(v TypedValue) (float64, bool) {
+	switch n := v.parsed.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// Classes of cell values when sorting by value, with numbers before dates and dates before text
+const (
+	classNumber = iota
+	classDate
+	classText
+)
+
+// Returns the class a typed value sorts in
+func valueClass(v TypedValue) int {
+	if _, ok := numericValue(v); ok {
+		return classNumber
+	}
+	if _, ok := v.parsed.(time.Time); ok {
+		return classDate
+	}
+	return classText
+}
+
+// Compares cell values for sorting by value, first by class and then within it, so numbers compare by value and 9 sorts before 10,
+// dates compare by time, and everything else compares by its text
+// Comparing classes first keeps the order consistent for columns mixing numbers, dates, and text
+func compareValues(a DataValue, b DataValue) int {
+	a_class, b_class := valueClass(a.typed), valueClass(b.typed)
+	if a_class != b_class {
+		return cmp.Compare(a_class, b_class)
+	}
+	switch a_class {
+	case classNumber:
+		a_num, _ := numericValue(a.typed)
+		b_num, _ := numericValue(b.typed)
+		return cmp.Compare(a_num, b_num)
+	case classDate:
+		return a.typed.parsed.(time.Time).Compare(b.typed.parsed.(time.Time))
+	}
+	return strings.Compare(a.val, b.val)
+}
+
+// Orders cells for formatters that produce one statement per cell
+func sortCells(cells []DataValue, d string, s string) []DataValue {
+	out := slices.Clone(cells)
+	switch s {
+	case SortHeader:
+		slices.SortStableFunc(out, func(a, b DataValue) int {
+			a_x, a_y := a.JoinHeaders(d)
+			b_x, b_y := b.JoinHeaders(d)
+			return cmp.Or(strings.Compare(a_x, b_x), strings.Compare(a_y, b_y))
+		})
+	case SortValue:
+		slices.SortStableFunc(out, compareValues)
+	}
+	return out
+}
+
+// Orders cells for formatters that list the values of each row (by_row) or column in one statement
+// Sorting by header orders statements by their row/column header and the values within them by the other header
+// Sorting by value keeps statements in table order and orders the values within them
+func sortGroupedCells(cells []DataValue, d string, s string, by_row bool) []DataValue {
+	heads := func(v DataValue) (string, string) {
+		x_head, y_head := v.JoinHeaders(d)
+		if by_row {
+			return x_head, y_head
+		}
+		return y_head, x_head
+	}
+	first := map[string]int{}
+	for i, cell := range cells {
+		group, _ := heads(cell)
+		if _, ok := first[group]; !ok {
+			first[group] = i
+		}
+	}
+
+	out := slices.Clone(cells)
+	switch s {
+	case SortHeader:
+		slices.SortStableFunc(out, func(a, b DataValue) int {
+			a_group, a_item := heads(a)
+			b_group, b_item := heads(b)
+			return cmp.Or(strings.Compare(a_group, b_group), strings.Compare(a_item, b_item))
+		})
+	case SortValue:
+		slices.SortStableFunc(out, func(a, b DataValue) int {
+			a_group, _ := heads(a)
+			b_group, _ := heads(b)
+			return cmp.Or(cmp.Compare(first[a_group], first[b_group]), compareValues(a, b))
+		})
+	}
+	return out
+}
+
+// Collects listed values under their statement ids, keeping ids in the order they are first added
+type statementGroups struct {
+	ids   []string
+	items map[string][]string
+}
+
+func newStatementGroups() *statementGroups {
+	return &statementGroups{[]string{}, map[string][]string{}}
+}
+
+// Adds a listed value to the statement with the given id
+func (g *statementGroups) add(id string, item string) {
+	if _, ok := g.items[id]; !ok {
+		g.ids = append(g.ids, id)
+	}
+	g.items[id] = append(g.items[id], item)
+}
+
+// Implemented by TableFormatters which can format any run of cells on their own, without the rest of the table
+// Streaming uses this to format a table one row at a time
+type CellFormatter interface {
+	formatCells(cells []DataValue, ff FormatFields) []string
 }
 
 // Reformats DataValue structs into natural language for formatters that don't rely on arrays
-func format_from_cells(t TableData, d string, f string, values ...any) []string {
+// <x_named> and <y_named> describe the headers along with their labels, as in "size is large" or "year is 2024 and quarter is Q1"
+func format_cells(cells []DataValue, ff FormatFields, f string, values ...any) []string {
 	out := []string{}
-	for _, cell := range t.cells {
+	for _, cell := range cells {
 		str := fmt.Sprintf(f, values...)
-		x_head, y_head := cell.JoinHeaders(d)
+		x_head, y_head := cell.JoinHeaders(ff.Delim)
+		x_named, y_named := cell.DescribeHeaders(ff)
 		str = strings.Replace(str, "<x_head>", x_head, -1)
 		str = strings.Replace(str, "<y_head>", y_head, -1)
+		str = strings.Replace(str, "<x_named>", x_named, -1)
+		str = strings.Replace(str, "<y_named>", y_named, -1)
 		str = strings.Replace(str, "<cell_val>", cell.val, -1)
 		str = strings.Replace(str, "  ", " ", -1)
 		str = strings.TrimSpace(str)
@@ -37,8 +181,13 @@ type CustomFormatter struct {
 }
 
 // Formats DataValue data based on custom format string and specified values
-func (f *CustomFormatter) format(ff FormatFields) []string {
-	return format_from_cells(f.TableData, ff.Delim, f.f_str, f.values...)
+func (f *CustomFormatter) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortCells(f.dataCells(), ff.Delim, ff.Sort), ff), nil
+}
+
+// Formats the given cells in order
+func (f *CustomFormatter) formatCells(cells []DataValue, ff FormatFields) []string {
+	return format_cells(cells, ff, f.f_str, f.values...)
 }
 
 type UnnamedCoordFormatter1 struct {
@@ -47,8 +196,13 @@ type UnnamedCoordFormatter1 struct {
 
 // Format string: (val_label) (link) <x_head> and <y_head> (eq) <value>
 // Example: price for extra pepperoni and no cheese is $12.00
-func (f *UnnamedCoordFormatter1) format(ff FormatFields) []string {
-	return format_from_cells(f.TableData, ff.Delim, "%s %s <x_head> and <y_head> %s <cell_val> \n", ff.ValLabel, ff.Link, ff.Eq)
+func (f *UnnamedCoordFormatter1) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortCells(f.dataCells(), ff.Delim, ff.Sort), ff), nil
+}
+
+// Formats the given cells in order
+func (f *UnnamedCoordFormatter1) formatCells(cells []DataValue, ff FormatFields) []string {
+	return format_cells(cells, ff, "%s %s <x_head> and <y_head> %s <cell_val> \n", ff.ValLabel, ff.Link, ff.Eq)
 }
 
 type UnnamedCoordFormatter2 struct {
@@ -57,8 +211,13 @@ type UnnamedCoordFormatter2 struct {
 
 // Format string: (link) <x_head> and (y_label), (val_label) (eq) <value>
 // Example: For Extra pepperoni and no cheese, price will be $12.00
-func (f *UnnamedCoordFormatter2) format(ff FormatFields) []string {
-	return format_from_cells(f.TableData, ff.Delim, "%s <x_head> and <y_head> %s %s <cell_val> \n", ff.Link, ff.ValLabel, ff.Eq)
+func (f *UnnamedCoordFormatter2) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortCells(f.dataCells(), ff.Delim, ff.Sort), ff), nil
+}
+
+// Formats the given cells in order
+func (f *UnnamedCoordFormatter2) formatCells(cells []DataValue, ff FormatFields) []string {
+	return format_cells(cells, ff, "%s <x_head> and <y_head> %s %s <cell_val> \n", ff.Link, ff.ValLabel, ff.Eq)
 }
 
 type NamedCoordFormatter1 struct {
@@ -67,8 +226,13 @@ type NamedCoordFormatter1 struct {
 
 // Format string: (val_label) (link) (x_label) (eq) <x_head> and (y_label) (eq) <y_head> (eq) <value>
 // Example: Price when size is medium and crust is thin is $15
-func (f *NamedCoordFormatter1) format(ff FormatFields) []string {
-	return format_from_cells(f.TableData, ff.Delim, "%s %s %s %s <x_head> and %s %s <y_head> %s <cell_val> \n", ff.ValLabel, ff.Link, ff.XLabel, ff.Eq, ff.YLabel, ff.Eq, ff.Eq)
+func (f *NamedCoordFormatter1) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortCells(f.dataCells(), ff.Delim, ff.Sort), ff), nil
+}
+
+// Formats the given cells in order
+func (f *NamedCoordFormatter1) formatCells(cells []DataValue, ff FormatFields) []string {
+	return format_cells(cells, ff, "%s %s <x_named> and <y_named> %s <cell_val> \n", ff.ValLabel, ff.Link, ff.Eq)
 }
 
 type NamedCoordFormatter2 struct {
@@ -77,8 +241,13 @@ type NamedCoordFormatter2 struct {
 
 // Format string: (link) (x_label) (eq) <x_head> and (y_label) (eq) <y_head>, (val_label) (eq) <value>
 // Example: When size = medium and crust = thin, price = $15
-func (f *NamedCoordFormatter2) format(ff FormatFields) []string {
-	return format_from_cells(f.TableData, ff.Delim, "%s %s %s <x_head> and %s %s <y_head>, %s %s <cell_val> \n", ff.Link, ff.XLabel, ff.Eq, ff.YLabel, ff.Eq, ff.ValLabel, ff.Eq)
+func (f *NamedCoordFormatter2) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortCells(f.dataCells(), ff.Delim, ff.Sort), ff), nil
+}
+
+// Formats the given cells in order
+func (f *NamedCoordFormatter2) formatCells(cells []DataValue, ff FormatFields) []string {
+	return format_cells(cells, ff, "%s <x_named> and <y_named>, %s %s <cell_val> \n", ff.Link, ff.ValLabel, ff.Eq)
 }
 
 type NamedRowFormatter struct {
@@ -87,8 +256,13 @@ type NamedRowFormatter struct {
 
 // Format string: (link) (x_label) (eq) <x_head>, <y_head> (eq) <value>
 // Example: If topping is meat, vegan is false
-func (f *NamedRowFormatter) format(ff FormatFields) []string {
-	return format_from_cells(f.TableData, ff.Delim, "%s %s %s <x_head>, <y_head> %s <cell_val> \n", ff.Link, ff.XLabel, ff.Eq, ff.Eq)
+func (f *NamedRowFormatter) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortCells(f.dataCells(), ff.Delim, ff.Sort), ff), nil
+}
+
+// Formats the given cells in order
+func (f *NamedRowFormatter) formatCells(cells []DataValue, ff FormatFields) []string {
+	return format_cells(cells, ff, "%s <x_named>, <y_head> %s <cell_val> \n", ff.Link, ff.Eq)
 }
 
 type NamedColFormatter struct {
@@ -97,8 +271,13 @@ type NamedColFormatter struct {
 
 // Format string: (link) (y_label) (eq) <y_head>, <x_head> (eq) <value>
 // Example: If crust is gluten free, price increases by $3
-func (f *NamedColFormatter) format(ff FormatFields) []string {
-	return format_from_cells(f.TableData, ff.Delim, "%s %s %s <y_head>, <x_head> %s <cell_val> \n", ff.Link, ff.YLabel, ff.Eq, ff.Eq)
+func (f *NamedColFormatter) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortCells(f.dataCells(), ff.Delim, ff.Sort), ff), nil
+}
+
+// Formats the given cells in order
+func (f *NamedColFormatter) formatCells(cells []DataValue, ff FormatFields) []string {
+	return format_cells(cells, ff, "%s <y_named>, <x_head> %s <cell_val> \n", ff.Link, ff.Eq)
 }
 
 type UnnamedRowKeyValFormatter struct {
@@ -107,18 +286,23 @@ type UnnamedRowKeyValFormatter struct {
 
 // Format string: (link) (x_head),  [(y_head) (eq) <value>]
 // Example: For daily specials, [Monday is none, Tuesday is taco pizza, Wednesday is wing pizza]
-func (f *UnnamedRowKeyValFormatter) format(ff FormatFields) []string {
-	outmap := map[string][]string{}
-	for _, cell := range f.cells {
+func (f *UnnamedRowKeyValFormatter) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortGroupedCells(f.dataCells(), ff.Delim, ff.Sort, true), ff), nil
+}
+
+// Formats the given cells in order, with one statement per row header
+func (f *UnnamedRowKeyValFormatter) formatCells(cells []DataValue, ff FormatFields) []string {
+	groups := newStatementGroups()
+	for _, cell := range cells {
 		x_head, y_head := cell.JoinHeaders(ff.Delim)
 		id := fmt.Sprintf("%s %s", ff.Link, x_head)
 		str := fmt.Sprintf("%s %s %s", y_head, ff.Eq, cell.val)
-		outmap[id] = append(outmap[id], str)
+		groups.add(id, str)
 	}
 
 	out := []string{}
-	for id, arr := range outmap {
-		str := fmt.Sprintf("%s, %s", id, strings.Join(arr, ", "))
+	for _, id := range groups.ids {
+		str := fmt.Sprintf("%s, %s", id, strings.Join(groups.items[id], ", "))
 		out = append(out, str)
 	}
 	return out
@@ -130,21 +314,21 @@ type UnnamedColKeyValFormatter struct {
 
 // Format string: (link) (y_head), [(x_head) (eq) <value>]
 // Example: For sides, [Wings are $5, Mozz sticks are $7, Cheese curds are $6]
-func (f *UnnamedColKeyValFormatter) format(ff FormatFields) []string {
-	outmap := map[string][]string{}
-	for _, cell := range f.cells {
+func (f *UnnamedColKeyValFormatter) format(ff FormatFields) ([]string, error) {
+	groups := newStatementGroups()
+	for _, cell := range sortGroupedCells(f.dataCells(), ff.Delim, ff.Sort, false) {
 		x_head, y_head := cell.JoinHeaders(ff.Delim)
 		id := fmt.Sprintf("%s %s", ff.Link, y_head)
 		str := fmt.Sprintf("%s %s %s", x_head, ff.Eq, cell.val)
-		outmap[id] = append(outmap[id], str)
+		groups.add(id, str)
 	}
 
 	out := []string{}
-	for id, arr := range outmap {
-		str := fmt.Sprintf("%s, %s", id, strings.Join(arr, ", "))
+	for _, id := range groups.ids {
+		str := fmt.Sprintf("%s, %s", id, strings.Join(groups.items[id], ", "))
 		out = append(out, str)
 	}
-	return out
+	return out, nil
 }
 
 type NamedRowKeyValFormatter struct {
@@ -153,18 +337,24 @@ type NamedRowKeyValFormatter struct {
 
 // Format string: (link) (x_label) (eq) <x_head>, [(y_head) (eq) <value>]
 // Example: When country = South Korea, [Dominos is #1, Pizza Alvolo is #2, PizzaHut is #3]
-func (f *NamedRowKeyValFormatter) format(ff FormatFields) []string {
-	outmap := map[string][]string{}
-	for _, cell := range f.cells {
-		x_head, y_head := cell.JoinHeaders(ff.Delim)
-		id := fmt.Sprintf("%s %s %s %s", ff.Link, ff.XLabel, ff.Eq, x_head)
+func (f *NamedRowKeyValFormatter) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortGroupedCells(f.dataCells(), ff.Delim, ff.Sort, true), ff), nil
+}
+
+// Formats the given cells in order, with one statement per row header
+func (f *NamedRowKeyValFormatter) formatCells(cells []DataValue, ff FormatFields) []string {
+	groups := newStatementGroups()
+	for _, cell := range cells {
+		_, y_head := cell.JoinHeaders(ff.Delim)
+		x_named, _ := cell.DescribeHeaders(ff)
+		id := fmt.Sprintf("%s %s", ff.Link, x_named)
 		str := fmt.Sprintf("%s %s %s", y_head, ff.Eq, cell.val)
-		outmap[id] = append(outmap[id], str)
+		groups.add(id, str)
 	}
 
 	out := []string{}
-	for id, arr := range outmap {
-		str := fmt.Sprintf("%s, %s", id, strings.Join(arr, ", "))
+	for _, id := range groups.ids {
+		str := fmt.Sprintf("%s, %s", id, strings.Join(groups.items[id], ", "))
 		out = append(out, str)
 	}
 	return out
@@ -176,21 +366,22 @@ type NamedColKeyValFormatter struct {
 
 // Format string: (link) (y_label) (eq) <y_head>, [(x_head) (eq) <value>]
 // Example: In the case that chain is Sbarro, [locations is 600, year founded is 1956, hq is Columbus, Ohio]
-func (f *NamedColKeyValFormatter) format(ff FormatFields) []string {
-	outmap := map[string][]string{}
-	for _, cell := range f.cells {
-		x_head, y_head := cell.JoinHeaders(ff.Delim)
-		id := fmt.Sprintf("%s %s %s %s", ff.Link, ff.YLabel, ff.Eq, y_head)
+func (f *NamedColKeyValFormatter) format(ff FormatFields) ([]string, error) {
+	groups := newStatementGroups()
+	for _, cell := range sortGroupedCells(f.dataCells(), ff.Delim, ff.Sort, false) {
+		x_head, _ := cell.JoinHeaders(ff.Delim)
+		_, y_named := cell.DescribeHeaders(ff)
+		id := fmt.Sprintf("%s %s", ff.Link, y_named)
 		str := fmt.Sprintf("%s %s %s", x_head, ff.Eq, cell.val)
-		outmap[id] = append(outmap[id], str)
+		groups.add(id, str)
 	}
 
 	out := []string{}
-	for id, arr := range outmap {
-		str := fmt.Sprintf("%s, %s", id, strings.Join(arr, ", "))
+	for _, id := range groups.ids {
+		str := fmt.Sprintf("%s, %s", id, strings.Join(groups.items[id], ", "))
 		out = append(out, str)
 	}
-	return out
+	return out, nil
 }
 
 type RowValFormatter struct {
@@ -199,18 +390,23 @@ type RowValFormatter struct {
 
 // Format string: (pre) <x_head> (link) [<value>]
 // Example: All possible topping are [sausage, mushroom, olives]
-func (f *RowValFormatter) format(ff FormatFields) []string {
-	outmap := map[string][]string{}
-	for _, cell := range f.cells {
+func (f *RowValFormatter) format(ff FormatFields) ([]string, error) {
+	return f.formatCells(sortGroupedCells(f.dataCells(), ff.Delim, ff.Sort, true), ff), nil
+}
+
+// Formats the given cells in order, with one statement per row header
+func (f *RowValFormatter) formatCells(cells []DataValue, ff FormatFields) []string {
+	groups := newStatementGroups()
+	for _, cell := range cells {
 		x_head, _ := cell.JoinHeaders(ff.Delim)
 		id := fmt.Sprintf("%s %s %s", ff.Pre, x_head, ff.Link)
 		str := cell.val
-		outmap[id] = append(outmap[id], str)
+		groups.add(id, str)
 	}
 
 	out := []string{}
-	for id, arr := range outmap {
-		str := fmt.Sprintf("%s %s", id, strings.Join(arr, ", "))
+	for _, id := range groups.ids {
+		str := fmt.Sprintf("%s %s", id, strings.Join(groups.items[id], ", "))
 		out = append(out, str)
 	}
 	return out
@@ -222,19 +418,194 @@ type ColValFormatter struct {
 
 // Format string: (pre) <y_head> (link) [<value>]
 // Example: Size can be one of [small, medium, large]
-func (f *ColValFormatter) format(ff FormatFields) []string {
-	outmap := map[string][]string{}
-	for _, cell := range f.cells {
+func (f *ColValFormatter) format(ff FormatFields) ([]string, error) {
+	groups := newStatementGroups()
+	for _, cell := range sortGroupedCells(f.dataCells(), ff.Delim, ff.Sort, false) {
 		_, y_head := cell.JoinHeaders(ff.Delim)
 		id := fmt.Sprintf("%s %s %s", ff.Pre, y_head, ff.Link)
 		str := cell.val
-		outmap[id] = append(outmap[id], str)
+		groups.add(id, str)
 	}
 
 	out := []string{}
-	for id, arr := range outmap {
-		str := fmt.Sprintf("%s %s", id, strings.Join(arr, ", "))
+	for _, id := range groups.ids {
+		str := fmt.Sprintf("%s %s", id, strings.Join(groups.items[id], ", "))
 		out = append(out, str)
 	}
-	return out
+	return out, nil
+}
+
+// Helper functions available to templates used by the template formatters
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	// Uppercases the first letter of a string
+	"capitalize": func(s string) string {
+		r := []rune(s)
+		if len(r) == 0 {
+			return s
+		}
+		return string(unicode.ToUpper(r[0])) + string(r[1:])
+	},
+	// Joins a list of strings with the separator, as in {{join .Values ", "}}
+	"join": func(a []string, sep string) string {
+		return strings.Join(a, sep)
+	},
+	// Returns the fallback when the value is empty, as in {{default "n/a" .Value}}
+	"default": func(fallback string, s string) string {
+		if s == "" {
+			return fallback
+		}
+		return s
+	},
+	// Returns a single header level, or an empty string if the header has fewer levels, as in {{level .XHeads 0}}
+	"level": func(a []string, i int) string {
+		if i < 0 || i >= len(a) {
+			return ""
+		}
+		return a[i]
+	},
+}
+
+// Parses a template string from FormatFields
+func parseTemplate(s string) (*template.Template, error) {
+	tmpl, err := template.New("template").Funcs(templateFuncs).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse template %q: %w", s, err)
+	}
+	return tmpl, nil
+}
+
+// Executes a template, returning an error if it cannot be applied to the data
+func executeTemplate(tmpl *template.Template, data any) (string, error) {
+	out := strings.Builder{}
+	err := tmpl.Execute(&out, data)
+	if err != nil {
+		return "", fmt.Errorf("unable to apply template: %w", err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// Data available to per-cell templates
+type CellTemplateData struct {
+	// Row header, joined with delim
+	XHead string
+	// Column header, joined with delim
+	YHead string
+	// Each level of the row header
+	XHeads []string
+	// Each level of the column header
+	YHeads []string
+	// Each level of the row header along with its label from x_levels
+	XLevels []HeaderLevel
+	// Each level of the column header along with its label from y_levels
+	YLevels []HeaderLevel
+	// Cell value as written in the table
+	Value string
+	// Inferred or configured type of the cell value
+	Type ValueType
+	// Cell value parsed according to its type
+	Parsed any
+	// Position of the cell on the x axis, or its column
+	X int
+	// Position of the cell on the y axis, or its row
+	Y int
+	// User provided fields from the config
+	Fields FormatFields
+}
+
+// Data available to per-row and per-column templates
+type GroupTemplateData struct {
+	// Header of the row or column, joined with delim
+	Head string
+	// Each level of the row or column header
+	Heads []string
+	// Values of each cell in the row or column
+	Values []string
+	// Data for each cell in the row or column, with the same fields as per-cell templates
+	Cells []CellTemplateData
+	// User provided fields from the config
+	Fields FormatFields
+}
+
+// Collects the template data for a single cell
+func cellTemplateData(cell DataValue, ff FormatFields) CellTemplateData {
+	x_head, y_head := cell.JoinHeaders(ff.Delim)
+	x_levels, y_levels := cell.HeaderLevels(ff.XLevels, ff.YLevels)
+	return CellTemplateData{x_head, y_head, cell.x_head, cell.y_head, x_levels, y_levels, cell.val, cell.typed.kind, cell.typed.parsed, cell.x, cell.y, ff}
+}
+
+// Applies a template to the cells of each row (by_row) or column
+func format_from_groups(t TableData, tmpl *template.Template, ff FormatFields, by_row bool) ([]string, error) {
+	groups := map[string]*GroupTemplateData{}
+	ids := []string{}
+	for _, cell := range sortGroupedCells(t.dataCells(), ff.Delim, ff.Sort, by_row) {
+		x_head, y_head := cell.JoinHeaders(ff.Delim)
+		id, heads := x_head, cell.x_head
+		if !by_row {
+			id, heads = y_head, cell.y_head
+		}
+		if _, ok := groups[id]; !ok {
+			groups[id] = &GroupTemplateData{Head: id, Heads: heads, Values: []string{}, Cells: []CellTemplateData{}, Fields: ff}
+			ids = append(ids, id)
+		}
+		groups[id].Values = append(groups[id].Values, cell.val)
+		groups[id].Cells = append(groups[id].Cells, cellTemplateData(cell, ff))
+	}
+
+	out := []string{}
+	for i, id := range ids {
+		str, err := executeTemplate(tmpl, groups[id])
+		if err != nil {
+			if by_row {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			return nil, fmt.Errorf("column %d: %w", i+1, err)
+		}
+		out = append(out, str)
+	}
+	return out, nil
+}
+
+// Template formatters hold the template parsed from FormatFields when they are set up
+type TemplateFormatter struct {
+	TableData
+	tmpl *template.Template
+}
+
+// Format string: (template), applied to each cell with CellTemplateData
+// Example: {{.Fields.ValLabel}} for {{.XHead}} in {{.YHead}} is {{.Value}} -> price for large in thin crust is $15
+func (f *TemplateFormatter) format(ff FormatFields) ([]string, error) {
+	out := []string{}
+	for _, cell := range sortCells(f.dataCells(), ff.Delim, ff.Sort) {
+		str, err := executeTemplate(f.tmpl, cellTemplateData(cell, ff))
+		if err != nil {
+			return nil, fmt.Errorf("cell at row %d, column %d: %w", cell.y+1, cell.x+1, err)
+		}
+		out = append(out, str)
+	}
+	return out, nil
+}
+
+type RowTemplateFormatter struct {
+	TableData
+	tmpl *template.Template
+}
+
+// Format string: (template), applied to each row with GroupTemplateData
+// Example: {{.Head}} comes in {{join .Values ", "}} -> pepperoni comes in small, medium, large
+func (f *RowTemplateFormatter) format(ff FormatFields) ([]string, error) {
+	return format_from_groups(f.TableData, f.tmpl, ff, true)
+}
+
+type ColTemplateFormatter struct {
+	TableData
+	tmpl *template.Template
+}
+
+// Format string: (template), applied to each column with GroupTemplateData
+// Example: {{range .Cells}}{{.XHead}} costs {{.Value}}. {{end}} -> small costs $8. large costs $12.
+func (f *ColTemplateFormatter) format(ff FormatFields) ([]string, error) {
+	return format_from_groups(f.TableData, f.tmpl, ff, false)
 }
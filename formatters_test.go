@@ -8,11 +8,14 @@ package main
 import (
 	"fmt"
 	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
 )
 
 func reference_fields() (f1, f2 FormatFields) {
-	f1 = FormatFields{"", "", "", "", "", "", ""}
-	f2 = FormatFields{"delim", "link", "eq", "pre", "vallabel", "xlabel", "ylabel"}
+	f1 = FormatFields{"", "", "", "", "", "", "", "", "", nil, nil}
+	f2 = FormatFields{"delim", "link", "eq", "pre", "vallabel", "xlabel", "ylabel", "", "", nil, nil}
 	return f1, f2
 }
 
@@ -71,7 +74,10 @@ func TestUnnamedCoordFormatter1(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -133,7 +139,10 @@ func TestUnnamedCoordFormatter2(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -195,7 +204,10 @@ func TestNamedCoordFormatter1(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -257,7 +269,10 @@ func TestNamedCoordFormatter2(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -319,7 +334,10 @@ func TestNamedRowFormatter(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -381,7 +399,10 @@ func TestNamedColFormatter(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -405,7 +426,10 @@ func TestUnnamedRowKeyValFormatter(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -429,7 +453,10 @@ func TestUnnamedColKeyValFormatter(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -453,7 +480,10 @@ func TestNamedRowKeyValFormatter(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -477,7 +507,10 @@ func TestNamedColKeyValFormatter(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -501,7 +534,10 @@ func TestRowValFormatter(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
@@ -525,9 +561,338 @@ func TestColValFormatter(t *testing.T) {
 	}
 
 	for _, test := range table {
-		res := test.formatter.format(test.fields)
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
 			t.Errorf("%v.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
 		}
 	}
 }
+
+func TestCompareValues(t *testing.T) {
+	table := []struct {
+		a   string
+		b   string
+		exp int
+	}{
+		{"9", "10", -1},
+		{"10", "9", 1},
+		{"2.5", "10", -1},
+		{"$9.00", "$10.00", -1},
+		{"9%", "10%", -1},
+		{"1,200", "900", 1},
+		{"10", "10.0", 0},
+		{"2024-01-05", "2023-12-31", 1},
+		{"10", "apple", -1},
+		{"b", "a", 1},
+		{"2024-01-05", "9", 1},
+		{"2024-01-05", "apple", -1},
+		{"1a", "2", 1},
+	}
+
+	for _, test := range table {
+		a := DataValue{val: test.a, typed: InferType(test.a)}
+		b := DataValue{val: test.b, typed: InferType(test.b)}
+		res := compareValues(a, b)
+		if res != test.exp {
+			t.Errorf("compareValues(%q, %q) = %v, expected %v", test.a, test.b, res, test.exp)
+		}
+	}
+}
+
+func TestSortMixedValues(t *testing.T) {
+	exp := []string{"2", "10", "2024-01-01", "1a", "x"}
+	for _, input := range [][]string{
+		{"10", "1a", "2", "x", "2024-01-01"},
+		{"x", "2024-01-01", "2", "1a", "10"},
+		{"1a", "10", "x", "2024-01-01", "2"},
+	} {
+		cells := []DataValue{}
+		for i, val := range input {
+			cells = append(cells, DataValue{x: i, val: val, typed: InferType(val)})
+		}
+		res := []string{}
+		for _, cell := range sortCells(cells, "", SortValue) {
+			res = append(res, cell.val)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(exp) {
+			t.Errorf("sortCells(%q, %q) = %q, expected %q", input, SortValue, res, exp)
+		}
+	}
+}
+
+func TestFormatterSort(t *testing.T) {
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"_", "b", "a", "c"},
+			{"r2", "3", "1", "2"},
+			{"r1", "6", "0", "4"},
+		}, dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+	td := NewTableData(df, 1, 1)
+	numbers := NewTableData(dataframe.LoadRecords(
+		[][]string{
+			{"_", "a", "b"},
+			{"r2", "10", "9.5"},
+			{"r1", "9", "100"},
+		}, dataframe.DetectTypes(false), dataframe.DefaultType(series.String)), 1, 1)
+	table := []struct {
+		formatter TableFormatter
+		sort      string
+		exp       []string
+	}{
+		{&UnnamedCoordFormatter1{td}, "", []string{"for r2 and b is 3", "for r2 and a is 1", "for r2 and c is 2", "for r1 and b is 6", "for r1 and a is 0", "for r1 and c is 4"}},
+		{&UnnamedCoordFormatter1{td}, SortPosition, []string{"for r2 and b is 3", "for r2 and a is 1", "for r2 and c is 2", "for r1 and b is 6", "for r1 and a is 0", "for r1 and c is 4"}},
+		{&UnnamedCoordFormatter1{td}, SortHeader, []string{"for r1 and a is 0", "for r1 and b is 6", "for r1 and c is 4", "for r2 and a is 1", "for r2 and b is 3", "for r2 and c is 2"}},
+		{&UnnamedCoordFormatter1{td}, SortValue, []string{"for r1 and a is 0", "for r2 and a is 1", "for r2 and c is 2", "for r2 and b is 3", "for r1 and c is 4", "for r1 and b is 6"}},
+		{&UnnamedRowKeyValFormatter{td}, SortPosition, []string{"for r2, b is 3, a is 1, c is 2", "for r1, b is 6, a is 0, c is 4"}},
+		{&UnnamedRowKeyValFormatter{td}, SortHeader, []string{"for r1, a is 0, b is 6, c is 4", "for r2, a is 1, b is 3, c is 2"}},
+		{&UnnamedRowKeyValFormatter{td}, SortValue, []string{"for r2, a is 1, c is 2, b is 3", "for r1, a is 0, c is 4, b is 6"}},
+		{&UnnamedColKeyValFormatter{td}, SortPosition, []string{"for b, r2 is 3, r1 is 6", "for a, r2 is 1, r1 is 0", "for c, r2 is 2, r1 is 4"}},
+		{&UnnamedColKeyValFormatter{td}, SortHeader, []string{"for a, r1 is 0, r2 is 1", "for b, r1 is 6, r2 is 3", "for c, r1 is 4, r2 is 2"}},
+		{&UnnamedColKeyValFormatter{td}, SortValue, []string{"for b, r2 is 3, r1 is 6", "for a, r1 is 0, r2 is 1", "for c, r2 is 2, r1 is 4"}},
+		{&RowValFormatter{td}, SortPosition, []string{"all r2 are 3, 1, 2", "all r1 are 6, 0, 4"}},
+		{&RowValFormatter{td}, SortHeader, []string{"all r1 are 0, 6, 4", "all r2 are 1, 3, 2"}},
+		{&RowValFormatter{td}, SortValue, []string{"all r2 are 1, 2, 3", "all r1 are 0, 4, 6"}},
+		{&ColValFormatter{td}, SortPosition, []string{"all b are 3, 6", "all a are 1, 0", "all c are 2, 4"}},
+		{&ColValFormatter{td}, SortHeader, []string{"all a are 0, 1", "all b are 6, 3", "all c are 4, 2"}},
+		{&ColValFormatter{td}, SortValue, []string{"all b are 3, 6", "all a are 0, 1", "all c are 2, 4"}},
+		{&UnnamedCoordFormatter1{numbers}, SortValue, []string{"for r1 and a is 9", "for r2 and b is 9.5", "for r2 and a is 10", "for r1 and b is 100"}},
+		{&UnnamedRowKeyValFormatter{numbers}, SortValue, []string{"for r2, b is 9.5, a is 10", "for r1, a is 9, b is 100"}},
+	}
+
+	for _, test := range table {
+		fields := FormatFields{Link: "for", Eq: "is", Sort: test.sort}
+		if _, ok := test.formatter.(*RowValFormatter); ok {
+			fields = FormatFields{Pre: "all", Link: "are", Sort: test.sort}
+		}
+		if _, ok := test.formatter.(*ColValFormatter); ok {
+			fields = FormatFields{Pre: "all", Link: "are", Sort: test.sort}
+		}
+		for range 5 {
+			res, err := test.formatter.format(fields)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+				t.Errorf("%T.format(%v) = %q, expected %q", test.formatter, fields, res, test.exp)
+				break
+			}
+		}
+	}
+}
+
+func TestTemplateFormatters(t *testing.T) {
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"a", "b", "c1", "c2"},
+			{"g", "r1", "1", "2"},
+			{"g", "r2", "3", "x"},
+		}, dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+	td := NewTableData(df, 1, 2)
+	table := []struct {
+		formatter string
+		fields    FormatFields
+		exp       []string
+	}{
+		{"TemplateFormatter", FormatFields{ValLabel: "price", Template: "{{.Fields.ValLabel}} for {{level .XHeads 1}} in {{.YHead}} is {{.Value}} ({{.Type}}, {{.X}}, {{.Y}})"}, []string{
+			"price for r1 in c1 is 1 (int, 2, 1)",
+			"price for r1 in c2 is 2 (int, 3, 1)",
+			"price for r2 in c1 is 3 (int, 2, 2)",
+			"price for r2 in c2 is x (string, 3, 2)",
+		}},
+		{"TemplateFormatter", FormatFields{Delim: " ", Template: "  {{upper .XHead}} {{default \"is\" .Fields.Eq}} {{level .XHeads 5}}{{.Value}} "}, []string{
+			"G R1 is 1",
+			"G R1 is 2",
+			"G R2 is 3",
+			"G R2 is x",
+		}},
+		{"RowTemplateFormatter", FormatFields{Template: "{{capitalize (level .Heads 1)}}: {{join .Values \", \"}}"}, []string{
+			"R1: 1, 2",
+			"R2: 3, x",
+		}},
+		{"ColTemplateFormatter", FormatFields{Delim: "/", Template: "{{.Head}} {{range .Cells}}[{{.XHead}}={{.Value}}]{{end}}"}, []string{
+			"c1 [g/r1=1][g/r2=3]",
+			"c2 [g/r1=2][g/r2=x]",
+		}},
+		{"ColTemplateFormatter", FormatFields{Sort: SortHeader, Template: "{{.Fields.Sort}} {{.Head}} {{join .Values \"\"}}"}, []string{
+			"header c1 13",
+			"header c2 2x",
+		}},
+	}
+
+	for _, test := range table {
+		formatter, err := SetFormatter(td, test.formatter, test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		res, err := formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("%v.format(%v) = %q, expected %q", test.formatter, test.fields.Template, res, test.exp)
+		}
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"a", "c1", "c2"},
+			{"r1", "1", "2"},
+		}, dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+	td := NewTableData(df, 1, 1)
+	table := []struct {
+		formatter string
+		template  string
+		parse_err bool
+	}{
+		{"TemplateFormatter", "{{.Value", true},
+		{"RowTemplateFormatter", "{{join .Values}", true},
+		{"ColTemplateFormatter", "{{nope .Head}}", true},
+		{"TemplateFormatter", "{{.Row}}", false},
+		{"RowTemplateFormatter", "{{.Value}}", false},
+		{"ColTemplateFormatter", "{{level .Heads}}", false},
+	}
+
+	for _, test := range table {
+		fields := FormatFields{Template: test.template}
+		formatter, err := SetFormatter(td, test.formatter, fields)
+		if test.parse_err {
+			if err == nil {
+				t.Errorf("SetFormatter(%v) with template %q returned no error", test.formatter, test.template)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		res, err := formatter.format(fields)
+		if err == nil {
+			t.Errorf("%v.format(%q) = %q, expected an error", test.formatter, test.template, res)
+		}
+	}
+}
+
+func TestHeaderLevelFormatters(t *testing.T) {
+	tables, err := ReadTables(ConfigFields{InFile: "data/test_levels.csv", Parser: "CSV"})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	td, err := NewTableDataFromConfig(tables[0], ConfigFields{NRowHeaders: ref_int(1), NColHeaders: ref_int(2)})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	fields := FormatFields{Delim: " / ", Link: "when", Eq: "is", ValLabel: "sales", XLabel: "region", YLevels: []string{"year", "quarter"}}
+	level_template := "{{range .YLevels}}{{.Label}}={{.Value}} {{end}}-> {{.Value}}"
+	tmpl, err := parseTemplate(level_template)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	table := []struct {
+		formatter TableFormatter
+		fields    FormatFields
+		exp       []string
+	}{
+		{&NamedCoordFormatter2{td}, fields, []string{
+			"when region is east and year is 2024 and quarter is Q1, sales is 10",
+			"when region is east and year is 2024 and quarter is Q2, sales is 12",
+			"when region is east and year is 2025 and quarter is Q1, sales is 14",
+			"when region is east and year is 2025 and quarter is Q2, sales is 15",
+			"when region is west and year is 2024 and quarter is Q1, sales is 8",
+			"when region is west and year is 2024 and quarter is Q2, sales is 9",
+			"when region is west and year is 2025 and quarter is Q1, sales is 11",
+			"when region is west and year is 2025 and quarter is Q2, sales is 13",
+		}},
+		{&NamedCoordFormatter2{td}, FormatFields{Delim: " / ", Link: "when", Eq: "is", ValLabel: "sales", XLabel: "region", YLabel: "period"}, []string{
+			"when region is east and period is 2024 / Q1, sales is 10",
+			"when region is east and period is 2024 / Q2, sales is 12",
+			"when region is east and period is 2025 / Q1, sales is 14",
+			"when region is east and period is 2025 / Q2, sales is 15",
+			"when region is west and period is 2024 / Q1, sales is 8",
+			"when region is west and period is 2024 / Q2, sales is 9",
+			"when region is west and period is 2025 / Q1, sales is 11",
+			"when region is west and period is 2025 / Q2, sales is 13",
+		}},
+		{&NamedColKeyValFormatter{td}, fields, []string{
+			"when year is 2024 and quarter is Q1, east is 10, west is 8",
+			"when year is 2024 and quarter is Q2, east is 12, west is 9",
+			"when year is 2025 and quarter is Q1, east is 14, west is 11",
+			"when year is 2025 and quarter is Q2, east is 15, west is 13",
+		}},
+		{&TemplateFormatter{td, tmpl}, FormatFields{YLevels: []string{"year", "quarter"}, Template: level_template}, []string{
+			"year=2024 quarter=Q1 -> 10",
+			"year=2024 quarter=Q2 -> 12",
+			"year=2025 quarter=Q1 -> 14",
+			"year=2025 quarter=Q2 -> 15",
+			"year=2024 quarter=Q1 -> 8",
+			"year=2024 quarter=Q2 -> 9",
+			"year=2025 quarter=Q1 -> 11",
+			"year=2025 quarter=Q2 -> 13",
+		}},
+	}
+
+	for _, test := range table {
+		res, err := test.formatter.format(test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("%T.format(%v) = %v, expected %v", test.formatter, test.fields, res, test.exp)
+		}
+	}
+}
+
+func TestEmptyCellFormatters(t *testing.T) {
+	tables, err := ReadTables(ConfigFields{InFile: "data/test_nulls.csv", Parser: "CSV"})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	fields := FormatFields{Link: "for", Eq: "is"}
+	table := []struct {
+		config  ConfigFields
+		skipped int
+		coord   []string
+		keyval  []string
+	}{
+		{ConfigFields{}, 0,
+			[]string{"for pepperoni and price is 12", "for pepperoni and stock is NA", "for cheese and price is", "for cheese and stock is 4", "for veggie and price is N/A", "for veggie and stock is -"},
+			[]string{"for pepperoni, price is 12, stock is NA", "for cheese, price is , stock is 4", "for veggie, price is N/A, stock is  - "}},
+		{ConfigFields{EmptyCells: EmptySkip}, 4,
+			[]string{"for pepperoni and price is 12", "for cheese and stock is 4"},
+			[]string{"for pepperoni, price is 12", "for cheese, stock is 4"}},
+		{ConfigFields{EmptyCells: EmptySubstitute}, 0,
+			[]string{"for pepperoni and price is 12", "for pepperoni and stock is not available", "for cheese and price is not available", "for cheese and stock is 4", "for veggie and price is not available", "for veggie and stock is not available"},
+			[]string{"for pepperoni, price is 12, stock is not available", "for cheese, price is not available, stock is 4", "for veggie, price is not available, stock is not available"}},
+		{ConfigFields{EmptyCells: EmptySubstitute, EmptyText: "unknown", NullValues: []string{"na"}}, 0,
+			[]string{"for pepperoni and price is 12", "for pepperoni and stock is unknown", "for cheese and price is unknown", "for cheese and stock is 4", "for veggie and price is N/A", "for veggie and stock is -"},
+			[]string{"for pepperoni, price is 12, stock is unknown", "for cheese, price is unknown, stock is 4", "for veggie, price is N/A, stock is  - "}},
+	}
+
+	for _, test := range table {
+		test.config.NRowHeaders, test.config.NColHeaders = ref_int(1), ref_int(1)
+		td, err := NewTableDataFromConfig(tables[0], test.config)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if td.skippedCells() != test.skipped {
+			t.Errorf("skippedCells() with %q = %v, expected %v", test.config.EmptyCells, td.skippedCells(), test.skipped)
+		}
+		res, err := (&UnnamedCoordFormatter2{td}).format(fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.coord) {
+			t.Errorf("UnnamedCoordFormatter2.format() with %q = %q, expected %q", test.config.EmptyCells, res, test.coord)
+		}
+		res, err = (&UnnamedRowKeyValFormatter{td}).format(fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.keyval) {
+			t.Errorf("UnnamedRowKeyValFormatter.format() with %q = %q, expected %q", test.config.EmptyCells, res, test.keyval)
+		}
+	}
+}
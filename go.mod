@@ -7,6 +7,7 @@ require (
 	github.com/go-gota/gota v0.12.0
 	github.com/gomarkdown/markdown v0.0.0-20240626202925-2eda941fd024
 	github.com/urfave/cli v1.22.15
+	golang.org/x/text v0.21.0
 )
 
 require (
@@ -5,8 +5,12 @@
 
 /*
 nlt reformats tabular data to natural language
-The basic executable can handle c/tsv, html, md, and json/l formats and outputs to plain text
+The basic executable can handle c/tsv, html, md, json/l, xlsx, and ods formats and outputs to plain text
 Inputs are provided by either 1) config.json in the current directory, or 2) a user specified file given with -c flag
+A config file can declare a list of runs, each reading and reformatting its own table, which share the other fields of the file as defaults
+Config files are validated against config.schema.json when loaded, with a warning for each field the chosen formatters use that is left empty
+Input and output files, parser, and formatter can also be set with flags, which take precedence over the config
+A path of - reads input from stdin or writes output to stdout, with progress reported on stderr
 
 Usage:
 
@@ -18,32 +22,39 @@ Flags:
 		Path to user specified config.json file
 	-l
 		If nlt should run using config from lastrun.json
+	--in
+		Input file, or - for stdin. Can also be given as the path argument
+	--out
+		Output file, or - for stdout
+	--parser
+		FileParser to use, or auto to detect it, required when reading from stdin
+	--formatter
+		TableFormatter to use
+	--jobs
+		Number of input files to process concurrently in batch runs
+	--stream
+		Read and reformat CSV, TSV, and JSONL inputs one row at a time, for files too large to load at once
 */
 package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/urfave/cli"
 )
 
-// Saves the reformatted output slice to the specified path
-func writeOutput(s []string, p string) error {
-	bytes := []byte(strings.Join(s, "\n"))
-	err := os.WriteFile(p, bytes, 0o644)
-	return err
-}
-
-// Saves a copy of the current config to lastrun.json
-func writeLastrun(c ConfigFields, f FormatFields) error {
-	fields := struct {
-		ConfigFields
-		FormatFields
-	}{c, f}
+// Saves a copy of the current config to lastrun.json, listing each run in full when there's more than one
+func writeLastrun(runs []RunConfig) error {
+	var fields any = runs[0]
+	if len(runs) > 1 {
+		fields = struct {
+			Runs []RunConfig `json:"runs"`
+		}{runs}
+	}
 	bytes, err := json.Marshal(fields)
 	if err != nil {
 		return err
@@ -52,9 +63,41 @@ func writeLastrun(c ConfigFields, f FormatFields) error {
 	return err
 }
 
+// Overrides config fields with any values set on the command line
+// Returns an error if --parser or --formatter isn't a value the config schema allows
+func applyFlags(c ConfigFields, in, out, parser, formatter string, jobs int, stream bool) (ConfigFields, error) {
+	err := errors.Join(checkFlagValue("parser", parser, "parser"), checkFlagValue("formatter", formatter, "formatter"))
+	if err != nil {
+		return c, err
+	}
+	if in != "" {
+		c.InFile = in
+	}
+	if out != "" {
+		c.OutFile = out
+	}
+	if parser != "" {
+		c.Parser = parser
+	}
+	if formatter != "" {
+		c.Formatter = formatter
+		c.Formatters = nil
+	}
+	if jobs != 0 {
+		c.Jobs = jobs
+	}
+	if stream {
+		c.Stream = true
+	}
+	return c, nil
+}
+
 func main() {
 	var configPath string
 	var lastrun bool
+	var inPath, outPath, parser, formatter string
+	var jobs int
+	var stream bool
 
 	app := &cli.App{
 		Name:  "NLT",
@@ -71,46 +114,80 @@ func main() {
 				Usage:       "Use lastrun.json for the current run",
 				Destination: &lastrun,
 			},
+			&cli.StringFlag{
+				Name:        "in",
+				Usage:       "Input file, or - to read from stdin",
+				Destination: &inPath,
+			},
+			&cli.StringFlag{
+				Name:        "out",
+				Usage:       "Output file, or - to write to stdout",
+				Destination: &outPath,
+			},
+			&cli.StringFlag{
+				Name:        "parser",
+				Usage:       "FileParser to use, or auto to detect it, required when reading from stdin",
+				Destination: &parser,
+			},
+			&cli.StringFlag{
+				Name:        "formatter",
+				Usage:       "TableFormatter to use",
+				Destination: &formatter,
+			},
+			&cli.IntFlag{
+				Name:        "jobs",
+				Usage:       "Number of input files to process concurrently in batch runs",
+				Destination: &jobs,
+			},
+			&cli.BoolFlag{
+				Name:        "stream",
+				Usage:       "Read and reformat CSV, TSV, and JSONL inputs one row at a time",
+				Destination: &stream,
+			},
 		},
-		Action: func(*cli.Context) {
+		Action: func(ctx *cli.Context) {
 			if lastrun {
-				fmt.Println("lastrun true")
 				configPath = "./lastrun.json"
-				fmt.Printf("lastrunpath %s, configpath %s", "./lastrun.json", configPath)
 			}
-
-			fmt.Printf("Reading from config file at %s \n", configPath)
-
-			config, err := ReadConfig(configPath)
-			if err != nil {
-				log.Fatalf("Unable to load config fields\nError: %v", err)
+			if inPath == "" {
+				inPath = ctx.Args().First()
 			}
-			fmt.Printf("Config fields read as:\n%#v\n", config)
-
-			fields, err := ReadFields(configPath)
-			if err != nil {
-				log.Fatalf("Unable to load formatter fields\nError: %v", err)
+			// Piped input is written back out to stdout unless an output file is given
+			if inPath == StdStream && outPath == "" {
+				outPath = StdStream
 			}
-			fmt.Printf("Formatter fields read as:\n%#v\n", fields)
 
-			parser := SetParser(config.InFile, config.Parser)
-			df := parser.parse()
-			table := NewTableData(df, 0, 0)
-			fmt.Printf("Table read from %s \n", config.InFile)
-			fmt.Printf("Table:\n%v\n", df)
+			fmt.Fprintf(os.Stderr, "Reading from config file at %s \n", configPath)
 
-			formatter := SetFormatter(table, config.Formatter)
-			out := formatter.format(fields)
-			fmt.Printf("Table reformatted to natural language using %v\n", config.Formatter)
-			fmt.Printf("Output:\n%v\n", strings.Join(out, "\n"))
+			// The default config file is optional when the run is fully specified by flags
+			var notFound *FileNotFoundError
+			runs, err := ReadRuns(configPath)
+			optional := errors.As(err, &notFound) && !ctx.IsSet("c") && !lastrun
+			if err != nil && !optional {
+				log.Fatalf("Unable to load config\nError: %v", err)
+			}
+			if optional {
+				runs = []RunConfig{{}}
+			}
+			// Flags apply to every run, taking precedence over both shared and per run fields
+			for i := range runs {
+				runs[i].ConfigFields, err = applyFlags(runs[i].ConfigFields, inPath, outPath, parser, formatter, jobs, stream)
+				if err != nil {
+					log.Fatalf("Invalid flag\nError: %v", err)
+				}
+				fmt.Fprintf(os.Stderr, "Config fields read as:\n%#v\n", runs[i].ConfigFields)
+				fmt.Fprintf(os.Stderr, "Formatter fields read as:\n%#v\n", runs[i].FormatFields)
+				for _, warning := range FieldWarnings(runs[i].ConfigFields, runs[i].FormatFields) {
+					fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+				}
+			}
 
-			err = writeOutput(out, config.OutFile)
+			err = RunAll(runs)
 			if err != nil {
-				log.Fatalf("Unable to save output file\nError: %v", err)
+				log.Fatalf("Unable to reformat table\nError: %v", err)
 			}
-			fmt.Printf("Output written to %v\n", config.OutFile)
 
-			err = writeLastrun(config, fields)
+			err = writeLastrun(runs)
 			if err != nil {
 				log.Fatalf("Unable to save lastrun file\nError: %v", err)
 			}
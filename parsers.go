@@ -6,12 +6,20 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"io/fs"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/go-gota/gota/dataframe"
@@ -19,69 +27,511 @@ import (
 	"github.com/gomarkdown/markdown"
 	"github.com/gomarkdown/markdown/html"
 	"github.com/gomarkdown/markdown/parser"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
 )
 
-// Reads in a file and returns a bytes reader, for flexibility in different file formats
-func ReaderFromFile(p string) *bytes.Reader {
+// Input or output path which reads from stdin or writes to stdout
+const StdStream = "-"
+
+// Standard input, which can only be read once, so the bytes read from it are kept and replayed to later readers,
+// such as the parser reading it after its start was sniffed
+type stdinReader struct {
+	mu sync.Mutex
+	r  io.Reader
+	// Bytes read from r so far, which hold all of stdin once read is set
+	content []byte
+	read    bool
+	err     error
+}
+
+// Returns up to the first n bytes of stdin, reading no more than that so the rest can still be streamed
+func (s *stdinReader) prefix(n int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.read && len(s.content) < n {
+		want := n - len(s.content)
+		rest, err := io.ReadAll(io.LimitReader(s.r, int64(want)))
+		s.content = append(s.content, rest...)
+		s.read, s.err = len(rest) < want || err != nil, err
+	}
+	return s.content[:min(n, len(s.content))], s.err
+}
+
+// Reads in the whole of stdin, returning the same contents on every call
+func (s *stdinReader) readAll() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.read {
+		rest, err := io.ReadAll(s.r)
+		s.content = append(s.content, rest...)
+		s.read, s.err = true, err
+	}
+	return s.content, s.err
+}
+
+// Returns a reader over stdin, which replays the bytes already read before reading the rest of stdin as it goes
+func (s *stdinReader) reader() io.Reader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.read {
+		return bytes.NewReader(s.content)
+	}
+	return io.MultiReader(bytes.NewReader(s.content), s.r)
+}
+
+// Reader for the process's stdin, shared by every run since stdin itself is
+var stdin = &stdinReader{r: os.Stdin}
+
+// Reads in the contents of a file or stdin, returning a FileNotFoundError if the file doesn't exist
+func readFile(p string) ([]byte, error) {
+	if p == StdStream {
+		return stdin.readAll()
+	}
 	content, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &FileNotFoundError{p, err}
+	}
+	return content, err
+}
+
+// Reads in a file and returns a bytes reader, for flexibility in different file formats
+func ReaderFromFile(p string) (*bytes.Reader, error) {
+	content, err := readFile(p)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return bytes.NewReader(content)
+	return bytes.NewReader(content), nil
 }
 
-// Replaces target html tags with specified replacement
-func swapHtmlTags(d *goquery.Document, old string, new string) {
-	d.Find(old).Each(func(i int, s *goquery.Selection) {
-		for _, node := range s.Nodes {
-			node.Data = new
+// Selects tables from an html document by 0 based index, caption text, or css selector
+// An empty selection returns the first table in the document, and TableAll returns every table
+func selectTables(doc *goquery.Document, t string) (*goquery.Selection, bool) {
+	tables := doc.Find("table")
+	sel := tables.First()
+	i, err := strconv.Atoi(t)
+	switch {
+	case t == "":
+	case t == TableAll:
+		sel = tables
+	case err == nil:
+		sel = tables.Eq(i)
+	default:
+		sel = tables.FilterFunction(func(i int, s *goquery.Selection) bool {
+			return strings.EqualFold(tableCaption(s), strings.TrimSpace(t))
+		})
+		if sel.Length() == 0 {
+			sel = doc.Find(t).Find("table").AddBackFiltered("table")
 		}
-	})
+	}
+	return sel, sel.Length() > 0
 }
 
-// Replaces each target html element with its children elements
-func replaceWithChildren(d *goquery.Document, t string) {
-	d.Find(t).Each(func(i int, s *goquery.Selection) {
-		s.ReplaceWithNodes(s.Children().Nodes...)
+// Returns the trimmed caption text of an html table, or an empty string if it has none
+func tableCaption(s *goquery.Selection) string {
+	return strings.TrimSpace(s.ChildrenFiltered("caption").First().Text())
+}
+
+// Identifies a table by its caption, then its id, then its position in the document
+func tableLabel(s *goquery.Selection, i int) string {
+	if caption := tableCaption(s); caption != "" {
+		return caption
+	}
+	if id, ok := s.Attr("id"); ok && id != "" {
+		return id
+	}
+	return fmt.Sprintf("table %d", i)
+}
+
+// Reads the rows of an html table into a rectangular grid, placing cells according to their colspan and rowspan
+// Cells covered by a span either repeat the spanned value or are left empty, depending on the span mode
+// Also returns the [x, y] positions of header cells, meaning th cells and any cells inside thead
+func readHTMLGrid(s *goquery.Selection, spans string) ([][]string, map[[2]int]bool) {
+	records := [][]string{}
+	covered := map[[2]int]bool{}
+	heads := map[[2]int]bool{}
+	rows := s.Find("tr").FilterFunction(func(i int, r *goquery.Selection) bool {
+		return r.Closest("table").IsSelection(s)
 	})
+	rows.Each(func(y int, r *goquery.Selection) {
+		x := 0
+		r.ChildrenFiltered("td, th").Each(func(i int, c *goquery.Selection) {
+			for covered[[2]int{x, y}] {
+				x++
+			}
+			colspan := spanAttr(c, "colspan", maxColspan)
+			// Like browsers, rowspans stop at the last row of the table rather than adding rows
+			rowspan := min(spanAttr(c, "rowspan", maxRowspan), rows.Length()-y)
+			text := strings.Join(strings.Fields(c.Text()), " ")
+			head := goquery.NodeName(c) == "th" || goquery.NodeName(c.Parent().Parent()) == "thead"
+			for dy := range rowspan {
+				for dx := range colspan {
+					covered[[2]int{x + dx, y + dy}] = true
+					heads[[2]int{x + dx, y + dy}] = head
+					for len(records) <= y+dy {
+						records = append(records, []string{})
+					}
+					for len(records[y+dy]) <= x+dx {
+						records[y+dy] = append(records[y+dy], "")
+					}
+					if (dx == 0 && dy == 0) || spans != SpanEmpty {
+						records[y+dy][x+dx] = text
+					}
+				}
+			}
+			x += colspan
+		})
+		for len(records) <= y {
+			records = append(records, []string{})
+		}
+	})
+	return padRecords(records), heads
+}
+
+// Counts the header rows and columns marked up in an html table
+// Header rows are leading rows made up entirely of header cells, and header columns are the leading header cells shared by every remaining row
+func detectHeaders(records [][]string, heads map[[2]int]bool) (rows, cols int) {
+	isHead := func(y int, n int) bool {
+		for x := range n {
+			if !heads[[2]int{x, y}] {
+				return false
+			}
+		}
+		return true
+	}
+	for rows < len(records) && len(records[rows]) > 0 && isHead(rows, len(records[rows])) {
+		rows++
+	}
+	if rows == len(records) {
+		return rows, 0
+	}
+	cols = len(records[rows])
+	for y := rows; y < len(records); y++ {
+		for cols > 0 && !isHead(y, cols) {
+			cols--
+		}
+	}
+	return rows, cols
 }
 
-// Removes th, thead, and tbody elements from html tables for more predictable inputs
-func StandardizeTables(doc *goquery.Document) {
-	swapHtmlTags(doc, "th", "td")
-	replaceWithChildren(doc, "thead")
-	replaceWithChildren(doc, "tbody")
+// Largest colspan and rowspan read from a cell, matching the limits browsers apply
+const (
+	maxColspan = 1000
+	maxRowspan = 65534
+)
+
+// Reads a colspan or rowspan attribute, defaulting to 1 when missing or invalid and clamping it to limit
+func spanAttr(s *goquery.Selection, attr string, limit int) int {
+	n, err := strconv.Atoi(s.AttrOr(attr, "1"))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return min(n, limit)
+}
+
+// Reads the selected tables from an html document into labeled dataframes
+func readHTMLTables(doc *goquery.Document, p string, t string, spans string) ([]LabeledTable, error) {
+	sel, ok := selectTables(doc, t)
+	if !ok {
+		return nil, &NoTableError{p, t}
+	}
+
+	all := doc.Find("table")
+	out := []LabeledTable{}
+	var err error
+	sel.EachWithBreak(func(i int, s *goquery.Selection) bool {
+		label := tableLabel(s, all.IndexOfSelection(s))
+		records, heads := readHTMLGrid(s, spans)
+		if len(records) == 0 {
+			err = fmt.Errorf("no rows found in table %q in %s", label, p)
+			return false
+		}
+		rows, cols := detectHeaders(records, heads)
+		table, load_err := loadTable(records)
+		if load_err != nil {
+			err = load_err
+			return false
+		}
+		table.label, table.row_headers, table.col_headers = label, cols, rows
+		out = append(out, table)
+		return true
+	})
+	return out, err
 }
 
 type FileParser interface {
-	parse() dataframe.DataFrame
+	parse() (LabeledTable, error)
+}
+
+// Selects every table in a multi-table document
+const TableAll = "all"
+
+// Span modes for html cells covered by a colspan or rowspan
+const (
+	// Copies the spanned value into every cell it covers
+	SpanRepeat = "repeat"
+	// Keeps the spanned value in the first cell only, leaving the rest empty
+	SpanEmpty = "empty"
+)
+
+// A dataframe read from a document that can contain several tables, with a label identifying which table it came from
+type LabeledTable struct {
+	label string
+	df    dataframe.DataFrame
+	// Column names as written in the source, since dataframes rename blank and repeated names to keep them unique
+	header []string
+	// Number of leading columns marked up as row headers in the source document
+	row_headers int
+	// Number of leading rows marked up as column headers in the source document
+	col_headers int
+}
+
+// Loads records with a header row into a table, keeping the header row as written
+func loadTable(records [][]string) (LabeledTable, error) {
+	df := dataframe.LoadRecords(records, dataframe.DetectTypes(false), dataframe.DefaultType(series.String), dataframe.NaNValues(nil))
+	t := LabeledTable{df: df}
+	if len(records) > 0 {
+		t.header = slices.Clone(records[0])
+	}
+	return t, df.Err
+}
+
+// Loads objects into a table with one column for each key, in sorted order, as dataframes do
+func loadMaps(objs []map[string]interface{}) (LabeledTable, error) {
+	df := dataframe.LoadMaps(objs, dataframe.DetectTypes(false), dataframe.DefaultType(series.String), dataframe.NaNValues(nil))
+	keys := map[string]bool{}
+	for _, obj := range objs {
+		for key := range obj {
+			keys[key] = true
+		}
+	}
+	return LabeledTable{df: df, header: slices.Sorted(maps.Keys(keys))}, df.Err
+}
+
+// Returns the rows of a table, with its header row as written in the source rather than as renamed by the dataframe
+func (t LabeledTable) records() [][]string {
+	records := t.df.Records()
+	if len(records) > 0 && len(t.header) == len(records[0]) {
+		records[0] = slices.Clone(t.header)
+	}
+	return records
+}
+
+// Implemented by FileParsers for formats that can be read one row at a time, without loading the whole table into memory
+// The first row passed to fn holds the column names as written, as the header row of a parsed table does
+type RowParser interface {
+	FileParser
+	parseRows(fn func(row []string) error) error
+}
+
+// Implemented by FileParsers for formats that can contain more than one table
+type MultiTableParser interface {
+	FileParser
+	parseAll() ([]LabeledTable, error)
+}
+
+// Reads a single character dialect option, falling back to the default when unset
+func dialectRune(name string, v string, d rune) (rune, error) {
+	if v == "" {
+		return d, nil
+	}
+	r := []rune(v)
+	if len(r) != 1 {
+		return 0, fmt.Errorf("csv %s must be a single character, got %q", name, v)
+	}
+	return r[0], nil
+}
+
+// Swaps a custom quote character with ", since encoding/csv only supports double quotes
+// Swapping is its own inverse, so it's applied to the input text and again to each parsed field
+func swapQuote(q rune) func(rune) rune {
+	return func(r rune) rune {
+		switch r {
+		case q:
+			return '"'
+		case '"':
+			return q
+		}
+		return r
+	}
+}
+
+// Sets up a csv reader over delimited text according to the dialect options, using d as the default delimiter
+// Leading lines are skipped, and any custom quote character is swapped with " as the text is read
+// Also returns the quote character, so it can be swapped back in each parsed field
+func newDelimitedReader(text io.Reader, o CSVOptions, d rune) (*csv.Reader, rune, error) {
+	delim, err := dialectRune("delimiter", o.Delimiter, d)
+	if err != nil {
+		return nil, 0, err
+	}
+	quote, err := dialectRune("quote", o.Quote, '"')
+	if err != nil {
+		return nil, 0, err
+	}
+	comment, err := dialectRune("comment", o.Comment, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	br := bufio.NewReader(text)
+	for range o.SkipLines {
+		_, err := br.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	text = br
+	if quote != '"' {
+		text = transform.NewReader(br, runes.Map(swapQuote(quote)))
+	}
+
+	r := csv.NewReader(text)
+	r.Comma = delim
+	r.Comment = comment
+	r.LazyQuotes = o.LazyQuotes
+	r.TrimLeadingSpace = o.TrimLeadingSpace
+	if o.Ragged {
+		r.FieldsPerRecord = -1
+	}
+	return r, quote, nil
+}
+
+// Swaps a custom quote character back into the fields of a record
+func unswapQuotes(record []string, quote rune) {
+	if quote == '"' {
+		return
+	}
+	for i := range record {
+		record[i] = strings.Map(swapQuote(quote), record[i])
+	}
+}
+
+// Wraps an error reading delimited text, counting skipped lines in the line numbers it reports
+func delimitedError(p string, o CSVOptions, err error) error {
+	var parse_err *csv.ParseError
+	if errors.As(err, &parse_err) {
+		parse_err.StartLine += o.SkipLines
+		parse_err.Line += o.SkipLines
+	}
+	return fmt.Errorf("reading %s: %w", p, err)
+}
+
+// Reads delimited text into records according to the dialect options, using d as the default delimiter
+func readDelimited(content []byte, o CSVOptions, d rune) ([][]string, error) {
+	r, quote, err := newDelimitedReader(bytes.NewReader(content), o, d)
+	if err != nil {
+		return nil, err
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		unswapQuotes(record, quote)
+	}
+	return padRecords(records), nil
+}
+
+// Reads a delimited text file into a table, with errors reporting the line they occurred on
+func parseDelimited(p string, enc string, o CSVOptions, d rune) (LabeledTable, error) {
+	content, err := readText(p, enc)
+	if err != nil {
+		return LabeledTable{}, err
+	}
+	records, err := readDelimited(content, o, d)
+	if err != nil {
+		return LabeledTable{}, delimitedError(p, o, err)
+	}
+	return loadTable(records)
+}
+
+// Reads a delimited text file one record at a time, passing each to fn
+// Ragged rows are padded to the width of the first row, since the widest row isn't known until the end of the file
+func streamDelimited(p string, enc string, o CSVOptions, d rune, fn func(row []string) error) error {
+	f, err := openText(p, enc)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, quote, err := newDelimitedReader(f, o, d)
+	if err != nil {
+		return delimitedError(p, o, err)
+	}
+	width := 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return delimitedError(p, o, err)
+		}
+		unswapQuotes(record, quote)
+		if width == 0 {
+			width = len(record)
+		}
+		for len(record) < width {
+			record = append(record, "")
+		}
+		err = fn(record)
+		if err != nil {
+			return err
+		}
+	}
 }
 
 type CSVParser struct {
 	path string
+	// Dialect options, with , as the default delimiter
+	opts CSVOptions
+	// Character encoding of the file, detected when empty
+	encoding string
 }
 
-// Reads CSV file into dataframe
-func (p *CSVParser) parse() dataframe.DataFrame {
-	return dataframe.ReadCSV(ReaderFromFile(p.path), dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+// Reads CSV file into a table
+func (p *CSVParser) parse() (LabeledTable, error) {
+	return parseDelimited(p.path, p.encoding, p.opts, ',')
+}
+
+// Reads CSV file one row at a time
+func (p *CSVParser) parseRows(fn func(row []string) error) error {
+	return streamDelimited(p.path, p.encoding, p.opts, ',', fn)
 }
 
 type TSVParser struct {
 	path string
+	// Dialect options, with tab as the default delimiter
+	opts CSVOptions
+	// Character encoding of the file, detected when empty
+	encoding string
+}
+
+// Reads TSV file into a table
+func (p *TSVParser) parse() (LabeledTable, error) {
+	return parseDelimited(p.path, p.encoding, p.opts, '\t')
 }
 
-// Reads TSV file into dataframe
-func (p *TSVParser) parse() dataframe.DataFrame {
-	return dataframe.ReadCSV(ReaderFromFile(p.path), dataframe.WithDelimiter('\t'), dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+// Reads TSV file one row at a time
+func (p *TSVParser) parseRows(fn func(row []string) error) error {
+	return streamDelimited(p.path, p.encoding, p.opts, '\t', fn)
 }
 
 type JSONLinesParser struct {
 	path string
+	// Character encoding of the file, detected when empty
+	encoding string
 }
 
-// Reads JSONL file into dataframe
-func (p *JSONLinesParser) parse() dataframe.DataFrame {
-	dec := json.NewDecoder(ReaderFromFile(p.path))
+// Reads JSONL file into a table
+func (p *JSONLinesParser) parse() (LabeledTable, error) {
+	content, err := readText(p.path, p.encoding)
+	if err != nil {
+		return LabeledTable{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(content))
 	jsonl := []map[string]interface{}{}
 	for {
 		res := map[string]interface{}{}
@@ -90,77 +540,179 @@ func (p *JSONLinesParser) parse() dataframe.DataFrame {
 			break
 		}
 		if err != nil {
-			log.Fatal(err)
+			return LabeledTable{}, jsonError(p.path, content, dec.InputOffset(), err)
 		}
 		jsonl = append(jsonl, res)
 	}
-	return dataframe.LoadMaps(jsonl, dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+	return loadMaps(jsonl)
+}
+
+// Reads JSONL file one row at a time, with a first row of column names taken from the keys of the first object in sorted order
+// Values are written out the same way as in parse, and keys missing from an object are left empty
+func (p *JSONLinesParser) parseRows(fn func(row []string) error) error {
+	f, err := openText(p.path, p.encoding)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	names := []string{}
+	for line := 1; ; line++ {
+		text, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(bytes.TrimSpace(text)) > 0 {
+			res := map[string]interface{}{}
+			decode_err := json.Unmarshal(text, &res)
+			if decode_err != nil {
+				return &MalformedJSONError{p.path, line, decode_err}
+			}
+			if len(names) == 0 {
+				names = slices.Sorted(maps.Keys(res))
+				fn_err := fn(names)
+				if fn_err != nil {
+					return fn_err
+				}
+			}
+			// Columns are fixed by the first line, so a key it doesn't have can't be given a column
+			for _, key := range slices.Sorted(maps.Keys(res)) {
+				if !slices.Contains(names, key) {
+					return fmt.Errorf("line %d of %s has key %q, which the first line doesn't, and streamed JSONL takes its columns from the first line only", line, p.path, key)
+				}
+			}
+			row := make([]string, len(names))
+			for i, name := range names {
+				val, ok := res[name]
+				if ok {
+					row[i] = fmt.Sprint(val)
+				}
+			}
+			fn_err := fn(row)
+			if fn_err != nil {
+				return fn_err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
 }
 
 type JSONArrObjParser struct {
 	path string
+	// Character encoding of the file, detected when empty
+	encoding string
 }
 
-// Reads an array of objects JSON file into dataframe
-func (p *JSONArrObjParser) parse() dataframe.DataFrame {
-	return dataframe.ReadJSON(ReaderFromFile(p.path), dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+// Reads an array of objects JSON file into a table
+func (p *JSONArrObjParser) parse() (LabeledTable, error) {
+	content, err := readText(p.path, p.encoding)
+	if err != nil {
+		return LabeledTable{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	objs := []map[string]interface{}{}
+	err = dec.Decode(&objs)
+	if err != nil {
+		return LabeledTable{}, jsonError(p.path, content, dec.InputOffset(), err)
+	}
+	return loadMaps(objs)
 }
 
 type JSONArrArrParser struct {
 	path string
+	// Character encoding of the file, detected when empty
+	encoding string
 }
 
-// Reads an array of arrays JSON file into dataframe
-func (p *JSONArrArrParser) parse() dataframe.DataFrame {
-	dec := json.NewDecoder(ReaderFromFile(p.path))
+// Reads an array of arrays JSON file into a table
+func (p *JSONArrArrParser) parse() (LabeledTable, error) {
+	content, err := readText(p.path, p.encoding)
+	if err != nil {
+		return LabeledTable{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(content))
 	records := [][]string{}
-	err := dec.Decode(&records)
+	err = dec.Decode(&records)
+	if err != nil {
+		return LabeledTable{}, jsonError(p.path, content, dec.InputOffset(), err)
+	}
+	return loadTable(records)
+}
+
+// Returns the first of the tables read from a multi-table document
+func firstTable(tables []LabeledTable, err error) (LabeledTable, error) {
 	if err != nil {
-		log.Fatal(err)
+		return LabeledTable{}, err
 	}
-	return dataframe.LoadRecords(records, dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+	return tables[0], nil
 }
 
 type MDParser struct {
 	path string
+	// Table to read, by 0 based index, caption text, or css selector
+	table string
+	// How cells covered by colspan and rowspan are filled, SpanRepeat or SpanEmpty
+	spans string
+	// Character encoding of the file, detected when empty
+	encoding string
 }
 
-// Reads MD file into dataframe
-func (p *MDParser) parse() dataframe.DataFrame {
-	md, err := io.ReadAll(ReaderFromFile(p.path))
+// Converts MD file to an html document
+func (p *MDParser) document() (*goquery.Document, error) {
+	md, err := readText(p.path, p.encoding)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	html := markdown.Render(parser.New().Parse(md), html.NewRenderer(html.RendererOptions{}))
-	reader := strings.NewReader(string(html))
-	doc, err := goquery.NewDocumentFromReader(reader)
-	if err != nil {
-		log.Fatal(err)
-	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(html))
+}
 
-	StandardizeTables(doc)
-	out, err := doc.Html()
+// Reads selected table from MD file into a table
+func (p *MDParser) parse() (LabeledTable, error) {
+	return firstTable(p.parseAll())
+}
+
+// Reads all selected tables from MD file into labeled dataframes
+func (p *MDParser) parseAll() ([]LabeledTable, error) {
+	doc, err := p.document()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return dataframe.ReadHTML(strings.NewReader(out), dataframe.DetectTypes(false), dataframe.DefaultType(series.String))[0]
+	return readHTMLTables(doc, p.path, p.table, p.spans)
 }
 
 type HTMLParser struct {
 	path string
+	// Table to read, by 0 based index, caption text, or css selector
+	table string
+	// How cells covered by colspan and rowspan are filled, SpanRepeat or SpanEmpty
+	spans string
+	// Character encoding of the file, detected when empty
+	encoding string
 }
 
-// Reads HTML file into dataframe
-func (p *HTMLParser) parse() dataframe.DataFrame {
-	doc, err := goquery.NewDocumentFromReader(ReaderFromFile(p.path))
+// Reads HTML file to an html document
+func (p *HTMLParser) document() (*goquery.Document, error) {
+	content, err := readText(p.path, p.encoding)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(content))
+}
+
+// Reads selected table from HTML file into a table
+func (p *HTMLParser) parse() (LabeledTable, error) {
+	return firstTable(p.parseAll())
+}
 
-	StandardizeTables(doc)
-	out, err := doc.Html()
+// Reads all selected tables from HTML file into labeled dataframes
+func (p *HTMLParser) parseAll() ([]LabeledTable, error) {
+	doc, err := p.document()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return dataframe.ReadHTML(strings.NewReader(out), dataframe.DetectTypes(false), dataframe.DefaultType(series.String))[0]
+	return readHTMLTables(doc, p.path, p.table, p.spans)
 }
@@ -6,9 +6,15 @@
 package main
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"strings"
 	"testing"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/go-gota/gota/dataframe"
 	"github.com/go-gota/gota/series"
 )
@@ -44,21 +50,112 @@ func reference_dataframes() (df1, df2, df3 dataframe.DataFrame) {
 	return df1, df2, df3
 }
 
+func TestStdinReader(t *testing.T) {
+	s := &stdinReader{r: strings.NewReader("a,b\n1,2\n")}
+	for range 2 {
+		res, err := s.readAll()
+		if err != nil || string(res) != "a,b\n1,2\n" {
+			t.Errorf("stdinReader.readAll() = %q, %v, expected %q", res, err, "a,b\n1,2\n")
+		}
+	}
+	res, err := io.ReadAll(s.reader())
+	if err != nil || string(res) != "a,b\n1,2\n" {
+		t.Errorf("stdinReader.reader() read %q, %v, expected %q", res, err, "a,b\n1,2\n")
+	}
+
+	r := strings.NewReader("a,b\n1,2\n3,4\n")
+	s = &stdinReader{r: r}
+	res, err = s.prefix(4)
+	if err != nil || string(res) != "a,b\n" || r.Len() != 8 {
+		t.Errorf("stdinReader.prefix(4) = %q, %v with %v bytes left unread, expected %q with 8 bytes left", res, err, r.Len(), "a,b\n")
+	}
+	res, err = io.ReadAll(s.reader())
+	if err != nil || string(res) != "a,b\n1,2\n3,4\n" {
+		t.Errorf("stdinReader.reader() after prefix(4) read %q, %v, expected %q", res, err, "a,b\n1,2\n3,4\n")
+	}
+}
+
+func TestCSVParser(t *testing.T) {
+	df1, _, _ := reference_dataframes()
+	ragged := dataframe.LoadRecords(
+		[][]string{
+			{"_", "col1", "col2", "col3"},
+			{"row1", "val\"11", "val12", ""},
+			{"row2", "val21", "val22", "val23"},
+			{"row3", "val31", "", ""},
+			{"row4", "val41", "val42", "val43"},
+		},
+		dataframe.DetectTypes(false),
+		dataframe.DefaultType(series.String),
+	)
+	table := []struct {
+		f   FileParser
+		exp dataframe.DataFrame
+	}{
+		{&CSVParser{"data/test1.csv", CSVOptions{}, ""}, df1},
+		{&CSVParser{"data/test_semicolon.csv", CSVOptions{Delimiter: ";", Comment: "#", SkipLines: 2}, ""}, df1},
+		{&CSVParser{"data/test_pipe.csv", CSVOptions{Delimiter: "|", Quote: "'", TrimLeadingSpace: true}, ""}, df1},
+		{&CSVParser{"data/test_ragged.csv", CSVOptions{LazyQuotes: true, Ragged: true}, ""}, ragged},
+		{&TSVParser{"data/test1.tsv", CSVOptions{}, ""}, df1},
+	}
+
+	for _, test := range table {
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("%v.parse() = %v, expected %v", test.f, res.df, test.exp)
+		}
+	}
+}
+
+func TestCSVParserErrors(t *testing.T) {
+	table := []struct {
+		f        FileParser
+		exp_line int
+	}{
+		{&CSVParser{"data/test_ragged.csv", CSVOptions{Ragged: true}, ""}, 2},
+		{&CSVParser{"data/test_ragged.csv", CSVOptions{LazyQuotes: true}, ""}, 2},
+		{&CSVParser{"data/test_semicolon.csv", CSVOptions{Delimiter: ";", SkipLines: 2}, ""}, 4},
+	}
+
+	for _, test := range table {
+		_, err := test.f.parse()
+		var parse_err *csv.ParseError
+		if !errors.As(err, &parse_err) {
+			t.Errorf("%v.parse() returned error %v, expected %T", test.f, err, parse_err)
+			continue
+		}
+		if parse_err.Line != test.exp_line {
+			t.Errorf("%v.parse() reported line %v, expected %v", test.f, parse_err.Line, test.exp_line)
+		}
+	}
+
+	_, err := (&CSVParser{"data/test1.csv", CSVOptions{Delimiter: "::"}, ""}).parse()
+	if err == nil {
+		t.Errorf("CSVParser with delimiter \"::\" returned no error")
+	}
+}
+
 func TestTSVParser(t *testing.T) {
 	df1, df2, df3 := reference_dataframes()
 	table := [3]struct {
 		f   FileParser
 		exp dataframe.DataFrame
 	}{
-		{&TSVParser{"data/test1.tsv"}, df1},
-		{&TSVParser{"data/test2.tsv"}, df2},
-		{&TSVParser{"data/test3.tsv"}, df3},
+		{&TSVParser{"data/test1.tsv", CSVOptions{}, ""}, df1},
+		{&TSVParser{"data/test2.tsv", CSVOptions{}, ""}, df2},
+		{&TSVParser{"data/test3.tsv", CSVOptions{}, ""}, df3},
 	}
 
 	for _, test := range table {
-		res := test.f.parse()
-		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
-			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res, test.exp)
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res.df, test.exp)
 		}
 	}
 }
@@ -69,13 +166,16 @@ func TestJSONLinesParser(t *testing.T) {
 		f   FileParser
 		exp dataframe.DataFrame
 	}{
-		{&JSONLinesParser{"data/test1.jsonl"}, df1},
+		{&JSONLinesParser{"data/test1.jsonl", ""}, df1},
 	}
 
 	for _, test := range table {
-		res := test.f.parse()
-		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
-			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res, test.exp)
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res.df, test.exp)
 		}
 	}
 }
@@ -86,13 +186,16 @@ func TestJSONArrObjParser(t *testing.T) {
 		f   FileParser
 		exp dataframe.DataFrame
 	}{
-		{&JSONArrObjParser{"data/test_arr_obj1.json"}, df1},
+		{&JSONArrObjParser{"data/test_arr_obj1.json", ""}, df1},
 	}
 
 	for _, test := range table {
-		res := test.f.parse()
-		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
-			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res, test.exp)
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res.df, test.exp)
 		}
 	}
 }
@@ -103,52 +206,207 @@ func TestJSONArrArrParser(t *testing.T) {
 		f   FileParser
 		exp dataframe.DataFrame
 	}{
-		{&JSONArrArrParser{"data/test_arr_arr1.json"}, df1},
-		{&JSONArrArrParser{"data/test_arr_arr2.json"}, df2},
+		{&JSONArrArrParser{"data/test_arr_arr1.json", ""}, df1},
+		{&JSONArrArrParser{"data/test_arr_arr2.json", ""}, df2},
 	}
 
 	for _, test := range table {
-		res := test.f.parse()
-		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
-			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res, test.exp)
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res.df, test.exp)
 		}
 	}
 }
 
 func TestMDParser(t *testing.T) {
 	df1, df2, df3 := reference_dataframes()
-	table := [3]struct {
+	table := []struct {
 		f   FileParser
 		exp dataframe.DataFrame
 	}{
-		{&MDParser{"data/test1.md"}, df1},
-		{&MDParser{"data/test2.md"}, df2},
-		{&MDParser{"data/test3.md"}, df3},
+		{&MDParser{"data/test1.md", "", "", ""}, df1},
+		{&MDParser{"data/test2.md", "", "", ""}, df2},
+		{&MDParser{"data/test3.md", "", "", ""}, df3},
+		{&MDParser{"data/test4.md", "", "", ""}, df3},
+		{&MDParser{"data/test4.md", "1", "", ""}, df1},
 	}
 
 	for _, test := range table {
-		res := test.f.parse()
-		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
-			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res, test.exp)
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res.df, test.exp)
 		}
 	}
 }
 
 func TestHTMLParser(t *testing.T) {
 	df1, df2, df3 := reference_dataframes()
-	table := [3]struct {
+	table := []struct {
 		f   FileParser
 		exp dataframe.DataFrame
 	}{
-		{&HTMLParser{"data/test1.html"}, df1},
-		{&HTMLParser{"data/test2.html"}, df2},
-		{&HTMLParser{"data/test3.html"}, df3},
+		{&HTMLParser{"data/test1.html", "", "", ""}, df1},
+		{&HTMLParser{"data/test2.html", "", "", ""}, df2},
+		{&HTMLParser{"data/test3.html", "", "", ""}, df3},
+		{&HTMLParser{"data/test4.html", "", "", ""}, df3},
+		{&HTMLParser{"data/test4.html", "1", "", ""}, df1},
+		{&HTMLParser{"data/test4.html", "#zeros", "", ""}, df3},
+		{&HTMLParser{"data/test4.html", "table.reference", "", ""}, df1},
+		{&HTMLParser{"data/test4.html", "reference values", "", ""}, df1},
+		{&HTMLParser{"data/test4.html", "#wrapped", "", ""}, df2},
 	}
 
 	for _, test := range table {
-		res := test.f.parse()
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res.df, test.exp)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	df1, df2, df3 := reference_dataframes()
+	h1, h2, h3 := []string{"_", "col1", "col2", "col3"}, []string{"", "", "", ""}, []string{"0", "0", "0", "0"}
+	table := []struct {
+		f   MultiTableParser
+		exp []LabeledTable
+	}{
+		{&HTMLParser{"data/test1.html", TableAll, "", ""}, []LabeledTable{{"table 0", df1, h1, 1, 0}}},
+		{&HTMLParser{"data/test4.html", TableAll, "", ""}, []LabeledTable{{"zeros", df3, h3, 0, 1}, {"Reference values", df1, h1, 0, 1}, {"table 2", df2, h2, 0, 1}}},
+		{&HTMLParser{"data/test4.html", "div table", "", ""}, []LabeledTable{{"table 2", df2, h2, 0, 1}}},
+		{&MDParser{"data/test4.md", TableAll, "", ""}, []LabeledTable{{"table 0", df3, h3, 0, 1}, {"table 1", df1, h1, 0, 1}}},
+	}
+
+	for _, test := range table {
+		res, err := test.f.parseAll()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
 		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
-			t.Errorf("ReadConfig(%v) = %v, expected %v", test.f, res, test.exp)
+			t.Errorf("%v.parseAll() = %v, expected %v", test.f, res, test.exp)
+		}
+	}
+}
+
+func TestReadHTMLGrid(t *testing.T) {
+	table := []struct {
+		spans string
+		exp   [][]string
+	}{
+		{SpanRepeat, [][]string{
+			{"_", "group", "group", "col3"},
+			{"_", "col1", "col2", "sub3"},
+			{"row1", "val11", "val12", "val12"},
+			{"row2", "val21", "val22", "val23"},
+			{"row2", "val31", "val32", "val33"},
+		}},
+		{SpanEmpty, [][]string{
+			{"_", "group", "", "col3"},
+			{"", "col1", "col2", "sub3"},
+			{"row1", "val11", "val12", ""},
+			{"row2", "val21", "val22", "val23"},
+			{"", "val31", "val32", "val33"},
+		}},
+	}
+
+	for _, test := range table {
+		doc, err := (&HTMLParser{"data/test5.html", "", "", ""}).document()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		res, _ := readHTMLGrid(doc.Find("table"), test.spans)
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("readHTMLGrid(data/test5.html, %v) = %v, expected %v", test.spans, res, test.exp)
+		}
+	}
+}
+
+func TestReadHTMLGridSpanLimits(t *testing.T) {
+	table := []struct {
+		html string
+		rows int
+		cols int
+	}{
+		{`<table><tr><td colspan="100000000">a</td></tr><tr><td>b</td></tr></table>`, 2, maxColspan},
+		{`<table><tr><td rowspan="100000000">a</td><td>b</td></tr><tr><td>c</td></tr></table>`, 2, 2},
+		{`<table><tr><td colspan="-3" rowspan="x">a</td><td>b</td></tr></table>`, 1, 2},
+	}
+
+	for _, test := range table {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(test.html))
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		res, _ := readHTMLGrid(doc.Find("table"), SpanRepeat)
+		if len(res) != test.rows || len(res[0]) != test.cols {
+			t.Errorf("readHTMLGrid(%v) = %v rows of %v cells, expected %v rows of %v cells", test.html, len(res), len(res[0]), test.rows, test.cols)
+		}
+	}
+}
+
+func TestDetectHeaders(t *testing.T) {
+	table := []struct {
+		path     string
+		exp_rows int
+		exp_cols int
+	}{
+		{"data/test1.html", 0, 1},
+		{"data/test4.html", 1, 0},
+		{"data/test5.html", 2, 0},
+		{"data/test6.html", 2, 1},
+	}
+
+	for _, test := range table {
+		doc, err := (&HTMLParser{test.path, "", "", ""}).document()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		rows, cols := detectHeaders(readHTMLGrid(doc.Find("table").First(), SpanRepeat))
+		if rows != test.exp_rows || cols != test.exp_cols {
+			t.Errorf("detectHeaders(%v) = %v, %v, expected %v, %v", test.path, rows, cols, test.exp_rows, test.exp_cols)
+		}
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	var not_found *FileNotFoundError
+	var malformed *MalformedJSONError
+	var no_table *NoTableError
+	table := []struct {
+		f        FileParser
+		exp      any
+		exp_line int
+	}{
+		{&CSVParser{"data/missing.csv", CSVOptions{}, ""}, &not_found, 0},
+		{&HTMLParser{"data/missing.html", "", "", ""}, &not_found, 0},
+		{&XLSXParser{"data/missing.xlsx", "", ""}, &not_found, 0},
+		{&JSONLinesParser{"data/test_malformed.jsonl", ""}, &malformed, 3},
+		{&JSONArrObjParser{"data/test_malformed.json", ""}, &malformed, 4},
+		{&JSONArrArrParser{"data/test_malformed.json", ""}, &malformed, 4},
+		{&JSONArrArrParser{"data/test_arr_obj1.json", ""}, &malformed, 2},
+		{&HTMLParser{"data/test4.html", "missing caption", "", ""}, &no_table, 0},
+		{&MDParser{"data/test4.md", "9", "", ""}, &no_table, 0},
+	}
+
+	for _, test := range table {
+		_, err := test.f.parse()
+		if !errors.As(err, test.exp) {
+			t.Errorf("%v.parse() returned error %v, expected %T", test.f, err, test.exp)
+		}
+		if test.exp == &not_found && !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%v.parse() returned error %v, expected it to wrap fs.ErrNotExist", test.f, err)
+		}
+		if errors.As(err, &malformed) && malformed.Line != test.exp_line {
+			t.Errorf("%v.parse() reported line %v, expected %v", test.f, malformed.Line, test.exp_line)
 		}
 	}
 }
@@ -0,0 +1,318 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 02:41:08 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Saves the reformatted output slice to the specified path, creating its directory if needed, or writes it to stdout for StdStream
+func writeOutput(s []string, p string) error {
+	bytes := []byte(strings.Join(s, "\n"))
+	if p == StdStream {
+		_, err := os.Stdout.Write(append(bytes, '\n'))
+		return err
+	}
+	err := os.MkdirAll(filepath.Dir(p), 0o755)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(p, bytes, 0o644)
+	return err
+}
+
+// Prefixes each reformatted statement with a label identifying the table it came from
+func tagOutput(s []string, label string) []string {
+	out := []string{}
+	for _, str := range s {
+		out = append(out, fmt.Sprintf("%s: %s", label, str))
+	}
+	return out
+}
+
+// Reads all tables selected by the config from the input file, normalizing cell values and reshaping tables if the config asks for it
+// Parsers for single table formats return one unlabeled table
+func ReadTables(c ConfigFields) ([]LabeledTable, error) {
+	parser, err := SetParser(c)
+	if err != nil {
+		return nil, err
+	}
+	tables := []LabeledTable{}
+	multi, ok := parser.(MultiTableParser)
+	if ok {
+		all, err := multi.parseAll()
+		if err != nil {
+			return nil, err
+		}
+		tables = all
+	} else {
+		table, err := parser.parse()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	for i, t := range tables {
+		if len(c.Normalize) > 0 {
+			normalized, err := normalizeTable(t, c.Normalize)
+			if err != nil {
+				return nil, err
+			}
+			t = normalized
+		}
+		transformed, err := transformTable(t, c.Transform)
+		if err != nil {
+			return nil, err
+		}
+		tables[i] = transformed
+	}
+	return tables, nil
+}
+
+// Builds TableData for each table using the table shaping options in the config, then reformats each to natural language
+// With more than one formatter, every table is reformatted by the first formatter before moving on to the next
+// Returns the statements along with the number of empty cells left out of them
+func FormatTables(tables []LabeledTable, c ConfigFields, f FormatFields) ([]string, int, error) {
+	if c.Transpose {
+		f = f.transposed()
+	}
+	data := []TableData{}
+	skipped := 0
+	for _, t := range tables {
+		td, err := NewTableDataFromConfig(t, c)
+		if err != nil {
+			return nil, 0, err
+		}
+		skipped += td.skippedCells()
+		data = append(data, td)
+	}
+	out := []string{}
+	for _, name := range c.FormatterNames() {
+		for i, t := range tables {
+			formatter, err := SetFormatter(data[i], name, f)
+			if err != nil {
+				return nil, 0, err
+			}
+			res, err := formatter.format(f)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: %w", name, err)
+			}
+			if c.Table == TableAll {
+				res = tagOutput(res, t.label)
+			}
+			out = append(out, res...)
+		}
+	}
+	return out, skipped, nil
+}
+
+// Checks the config names a parser for the input file, detecting it first in auto mode and reporting the detected parser to w
+func resolveParser(c ConfigFields, w io.Writer) (ConfigFields, error) {
+	if c.InFile == StdStream && c.Parser == "" {
+		return c, fmt.Errorf("a parser must be specified when reading from stdin, either by name or as %q", ParserAuto)
+	}
+	if c.Parser != ParserAuto {
+		return c, nil
+	}
+	parser, err := DetectParser(c.InFile, c.Encoding)
+	if err != nil {
+		return c, err
+	}
+	c.Parser = parser
+	fmt.Fprintf(w, "Parser detected as %s\n", c.Parser)
+	if (c.Parser == "CSV" || c.Parser == "TSV") && c.CSV.Delimiter == "" {
+		c.CSV.Delimiter, err = DetectDelimiter(c.InFile, c.Encoding, c.CSV)
+		if err != nil {
+			return c, err
+		}
+		fmt.Fprintf(w, "Delimiter detected as %q\n", c.CSV.Delimiter)
+	}
+	return c, nil
+}
+
+// Reads and reformats a single input file, detecting its parser first if the config asks for it
+// Progress is reported to w
+// Returns the statements along with the number of empty cells left out of them
+func runFile(c ConfigFields, f FormatFields, w io.Writer) ([]string, int, error) {
+	c, err := resolveParser(c, w)
+	if err != nil {
+		return nil, 0, err
+	}
+	tables, err := ReadTables(c)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, t := range tables {
+		y, x := c.HeaderCounts(t)
+		fmt.Fprintf(w, "Table read from %s %s\n", c.InFile, t.label)
+		fmt.Fprintf(w, "Table:\n%v\n", t.df)
+		fmt.Fprintf(w, "Using %d column header rows and %d row header columns\n", y, x)
+		if c.Transpose {
+			fmt.Fprintf(w, "Transposing to %d column header rows and %d row header columns\n", x, y)
+		}
+	}
+
+	out, skipped, err := FormatTables(tables, c, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	fmt.Fprintf(w, "Table reformatted to natural language using %v\n", strings.Join(c.FormatterNames(), ", "))
+	if skipped > 0 {
+		fmt.Fprintf(w, "%d empty cells skipped\n", skipped)
+	}
+	fmt.Fprintf(w, "Output:\n%v\n", strings.Join(out, "\n"))
+	return out, skipped, nil
+}
+
+// Runs the pipeline for every input file matched by the config, continuing past files which fail
+// Files are read and reformatted by a pool of c.Jobs workers, with results, progress, and outputs kept in input order
+// An outfile naming pattern such as outputs/{stem}.txt gives each input its own output file,
+// otherwise outputs are combined into the outfile, prefixed with their input file when there's more than one
+func RunBatch(c ConfigFields, f FormatFields) ([]FileResult, error) {
+	if c.OutFile == "" {
+		c.OutFile = StdStream
+	}
+	inputs, err := ExpandInputs(c.InFile, c.Parser)
+	if err != nil {
+		return nil, err
+	}
+	err = checkOutputPaths(c.OutFile, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := min(max(c.Jobs, 1), len(inputs))
+	outs := make([][]string, len(inputs))
+	skips := make([]int, len(inputs))
+	errs := make([]error, len(inputs))
+	logs := make([]bytes.Buffer, len(inputs))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				file_config := c
+				file_config.InFile = inputs[i]
+				var w io.Writer = &logs[i]
+				if jobs == 1 {
+					w = os.Stderr
+				}
+				outs[i], skips[i], errs[i] = runFile(file_config, f, w)
+			}
+		}()
+	}
+	for i := range inputs {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	pattern := isOutputPattern(c.OutFile)
+	results := []FileResult{}
+	combined := []string{}
+	succeeded := 0
+	for i, in := range inputs {
+		os.Stderr.Write(logs[i].Bytes())
+		result := FileResult{in, c.OutFile, len(outs[i]), skips[i], errs[i]}
+		switch {
+		case result.Err != nil:
+		case pattern:
+			result.OutFile = outputPath(c.OutFile, in)
+			result.Err = writeOutput(outs[i], result.OutFile)
+		case len(inputs) > 1:
+			combined = append(combined, tagOutput(outs[i], in)...)
+		default:
+			combined = append(combined, outs[i]...)
+		}
+		if result.Err == nil {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	if !pattern && succeeded > 0 {
+		err = writeOutput(combined, c.OutFile)
+		if err != nil {
+			return results, err
+		}
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			fmt.Fprintf(os.Stderr, "Output written to %v\n", r.OutFile)
+		}
+	}
+	return results, nil
+}
+
+// Runs the full pipeline for a config, from reading the input files to saving the reformatted output
+// Progress is reported on stderr, so output can be written to stdout, which is also used when no output file is set
+// Runs over more than one input file report a per file summary, and return an error joining those of every file that failed
+func Run(c ConfigFields, f FormatFields) error {
+	run := RunBatch
+	if c.Stream {
+		run = RunStream
+	}
+	results, err := run(c, f)
+	if err != nil {
+		return err
+	}
+	if len(results) == 1 {
+		return results[0].Err
+	}
+
+	writeSummary(os.Stderr, results)
+	errs := []error{}
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.InFile, r.Err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(append([]error{fmt.Errorf("%d of %d files failed", len(errs), len(results))}, errs...)...)
+	}
+	return nil
+}
+
+// Runs the pipeline for each run declared in a config in turn, continuing past runs which fail
+// Returns an error joining those of every run that failed, numbered from 1 in the order the runs are declared
+func RunAll(runs []RunConfig) error {
+	outfiles := map[string]int{}
+	for i, r := range runs {
+		if r.OutFile == "" || r.OutFile == StdStream {
+			continue
+		}
+		j, ok := outfiles[r.OutFile]
+		if ok {
+			return fmt.Errorf("runs %d and %d both write to %s, list several formatters in one run to combine their outputs", j+1, i+1, r.OutFile)
+		}
+		outfiles[r.OutFile] = i
+	}
+	if len(runs) == 1 {
+		return Run(runs[0].ConfigFields, runs[0].FormatFields)
+	}
+
+	errs := []error{}
+	for i, r := range runs {
+		fmt.Fprintf(os.Stderr, "Starting run %d of %d\n", i+1, len(runs))
+		err := Run(r.ConfigFields, r.FormatFields)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("run %d: %w", i+1, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(append([]error{fmt.Errorf("%d of %d runs failed", len(errs), len(runs))}, errs...)...)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 02:41:08 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTagOutput(t *testing.T) {
+	table := []struct {
+		input []string
+		label string
+		exp   []string
+	}{
+		{[]string{}, "table 0", []string{}},
+		{[]string{"a is 1", "b is 2"}, "prices", []string{"prices: a is 1", "prices: b is 2"}},
+	}
+
+	for _, test := range table {
+		res := tagOutput(test.input, test.label)
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("tagOutput(%v, %v) = %v, expected %v", test.input, test.label, res, test.exp)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	table := []struct {
+		path string
+		exp  []string
+	}{
+		{"data/test_config3.json", []string{
+			"price for row1 and col1 is val11",
+			"price for row1 and col2 is val12",
+			"price for row1 and col3 is val13",
+			"price for row2 and col1 is val21",
+			"price for row2 and col2 is val22",
+			"price for row2 and col3 is val23",
+			"price for row3 and col1 is val31",
+			"price for row3 and col2 is val32",
+			"price for row3 and col3 is val33",
+			"price for row4 and col1 is val41",
+			"price for row4 and col2 is val42",
+			"price for row4 and col3 is val43",
+		}},
+		{"data/test_config4.json", []string{
+			"when row is row1 and column is col1, value is val11",
+			"when row is row1 and column is col2, value is val12",
+			"when row is row1 and column is col3, value is val13",
+			"when row is row2 and column is col1, value is val21",
+			"when row is row2 and column is col2, value is val22",
+			"when row is row2 and column is col3, value is val23",
+			"when row is row3 and column is col1, value is val31",
+			"when row is row3 and column is col2, value is val32",
+			"when row is row3 and column is col3, value is val33",
+			"when row is row4 and column is col1, value is val41",
+			"when row is row4 and column is col2, value is val42",
+			"when row is row4 and column is col3, value is val43",
+		}},
+	}
+
+	for _, test := range table {
+		config, err := ReadConfig(test.path)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		fields, err := ReadFields(test.path)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		config.OutFile = filepath.Join(t.TempDir(), "output.txt")
+
+		err = Run(config, fields)
+		if err != nil {
+			t.Errorf("Run(%v) returned error %v", test.path, err)
+		}
+		res, err := os.ReadFile(config.OutFile)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if string(res) != strings.Join(test.exp, "\n") {
+			t.Errorf("Run(%v) wrote %q, expected %q", test.path, res, strings.Join(test.exp, "\n"))
+		}
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	runs, err := ReadRuns("data/test_config5.json")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	exp := [][]string{
+		{
+			"price for row1 and col1 is val11",
+			"price for row1 and col2 is val12",
+		},
+		{
+			"when row is row1, col1 is val11, col2 is val12, col3 is val13",
+			"when row is row2, col1 is val21, col2 is val22, col3 is val23",
+			"when row is row3, col1 is val31, col2 is val32, col3 is val33",
+			"when row is row4, col1 is val41, col2 is val42, col3 is val43",
+			"when row is row1, col1 is val11",
+			"when row is row1, col2 is val12",
+		},
+		{
+			"price for and _ is row1",
+			"price for and col1 is val11",
+		},
+	}
+
+	for _, stream := range []bool{false, true} {
+		dir := t.TempDir()
+		for i := range runs {
+			runs[i].OutFile = filepath.Join(dir, filepath.Base(runs[i].OutFile))
+			runs[i].Stream = stream
+		}
+		err = RunAll(runs)
+		if err != nil {
+			t.Errorf("RunAll(%v) returned error %v", runs, err)
+		}
+		for i, r := range runs {
+			res, err := os.ReadFile(r.OutFile)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			if !strings.HasPrefix(string(res), strings.Join(exp[i], "\n")) {
+				t.Errorf("RunAll(%v) wrote %q for run %d, expected it to start with %q", runs, res, i+1, strings.Join(exp[i], "\n"))
+			}
+		}
+	}
+
+	runs[2].OutFile = runs[0].OutFile
+	err = RunAll(runs)
+	if err == nil || !strings.Contains(err.Error(), "runs 1 and 3 both write to") {
+		t.Errorf("RunAll(%v) returned error %v, expected outfile conflict", runs, err)
+	}
+}
+
+func TestRunStdStream(t *testing.T) {
+	prev_stdin, stdout := stdin, os.Stdout
+	defer func() { stdin, os.Stdout = prev_stdin, stdout }()
+
+	in, err := os.Open("data/test1.csv")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer in.Close()
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout.txt"))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer out.Close()
+	stdin, os.Stdout = &stdinReader{r: in}, out
+
+	config := ConfigFields{InFile: StdStream, OutFile: StdStream, Formatter: "UnnamedCoordFormatter1", Parser: "CSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(1)}
+	err = Run(config, FormatFields{Link: "for", Eq: "is", ValLabel: "price"})
+	if err != nil {
+		t.Errorf("Run(%v) returned error %v", config, err)
+	}
+	res, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	exp := "price for row1 and col1 is val11\n"
+	if !strings.HasPrefix(string(res), exp) || !strings.HasSuffix(string(res), "val43\n") {
+		t.Errorf("Run(%v) wrote %q to stdout, expected output starting with %q", config, res, exp)
+	}
+
+	config.Parser = ParserAuto
+	err = Run(config, FormatFields{})
+	if err != nil {
+		t.Errorf("Run(%v) returned error %v", config, err)
+	}
+
+	config.Parser = ""
+	err = Run(config, FormatFields{})
+	if err == nil {
+		t.Errorf("Run(%v) returned no error, expected an error for stdin without a parser", config)
+	}
+}
@@ -0,0 +1,262 @@
+// -*- coding: utf-8 -*-
+
+// Created on Mon Oct 19 10:04:37 AM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// JSON Schema for config files, published alongside the source as config.schema.json
+//
+//go:embed config.schema.json
+var configSchemaJSON []byte
+
+// The parts of JSON Schema used by config.schema.json
+type jsonSchema struct {
+	Ref        string                 `json:"$ref"`
+	Type       string                 `json:"type"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	// Either false to reject keys not listed in Properties, or the schema values of unlisted keys must match
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []string               `json:"enum"`
+	MinItems             *int                   `json:"minItems"`
+	Minimum              *float64               `json:"minimum"`
+	Defs                 map[string]*jsonSchema `json:"$defs"`
+}
+
+// Checks decoded JSON against a schema, collecting a ConfigError for each problem found
+type schemaValidator struct {
+	// Config file being validated
+	path string
+	// Top level schema, which $refs point into
+	root *jsonSchema
+	errs []error
+}
+
+// Validates the contents of a config file, decoded with json.Decoder.UseNumber, against config.schema.json
+// Returns an error joining a ConfigError for every problem found
+func validateConfig(p string, v any) error {
+	var root jsonSchema
+	err := json.Unmarshal(configSchemaJSON, &root)
+	if err != nil {
+		return err
+	}
+	s := schemaValidator{path: p, root: &root}
+	s.validate(v, &root, "")
+	return errors.Join(s.errs...)
+}
+
+func (s *schemaValidator) fail(loc string, msg string, args ...any) {
+	s.errs = append(s.errs, &ConfigError{s.path, loc, fmt.Sprintf(msg, args...)})
+}
+
+// Follows a $ref to its definition under $defs
+func (s *schemaValidator) resolve(schema *jsonSchema) *jsonSchema {
+	for schema.Ref != "" {
+		def, ok := s.root.Defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+		if !ok {
+			panic(fmt.Sprintf("config schema has no definition for %s", schema.Ref))
+		}
+		schema = def
+	}
+	return schema
+}
+
+func (s *schemaValidator) validate(v any, schema *jsonSchema, loc string) {
+	schema = s.resolve(schema)
+	switch schema.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			s.fail(loc, "expected an object, got %s", jsonKind(v))
+			return
+		}
+		s.validateObject(obj, schema, loc)
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			s.fail(loc, "expected a list, got %s", jsonKind(v))
+			return
+		}
+		if schema.MinItems != nil && len(arr) < *schema.MinItems {
+			s.fail(loc, "expected %d or more items", *schema.MinItems)
+		}
+		for i, item := range arr {
+			s.validate(item, schema.Items, fmt.Sprintf("%s[%d]", loc, i))
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			s.fail(loc, "expected a string, got %s", jsonKind(v))
+			return
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, str) {
+			options := enumOptions(schema.Enum)
+			suggestion := didYouMean(str, options)
+			if suggestion != "" {
+				s.fail(loc, "unknown value %q, did you mean %q?", str, suggestion)
+				return
+			}
+			s.fail(loc, "unknown value %q, expected one of %s", str, strings.Join(options, ", "))
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		_, err := n.Int64()
+		if !ok || err != nil {
+			s.fail(loc, "expected a whole number, got %s", jsonKind(v))
+			return
+		}
+		f, _ := n.Float64()
+		if schema.Minimum != nil && f < *schema.Minimum {
+			s.fail(loc, "expected a number of at least %v, got %v", *schema.Minimum, n)
+		}
+	case "boolean":
+		_, ok := v.(bool)
+		if !ok {
+			s.fail(loc, "expected true or false, got %s", jsonKind(v))
+		}
+	}
+}
+
+// Checks each key of an object against its listed properties, rejecting unlisted keys unless additionalProperties allows them
+func (s *schemaValidator) validateObject(obj map[string]any, schema *jsonSchema, loc string) {
+	known := []string{}
+	for k := range schema.Properties {
+		known = append(known, k)
+	}
+	sort.Strings(known)
+	keys := []string{}
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key_loc := k
+		if loc != "" {
+			key_loc = loc + "." + k
+		}
+		prop, ok := schema.Properties[k]
+		if ok {
+			s.validate(obj[k], prop, key_loc)
+			continue
+		}
+		if string(schema.AdditionalProperties) == "false" {
+			suggestion := didYouMean(k, known)
+			if suggestion != "" {
+				s.fail(loc, "unknown key %q, did you mean %q?", k, suggestion)
+				continue
+			}
+			s.fail(loc, "unknown key %q", k)
+			continue
+		}
+		if len(schema.AdditionalProperties) > 0 {
+			var extra jsonSchema
+			err := json.Unmarshal(schema.AdditionalProperties, &extra)
+			if err != nil {
+				panic(fmt.Sprintf("config schema has invalid additionalProperties at %s: %v", loc, err))
+			}
+			s.validate(obj[k], &extra, key_loc)
+		}
+	}
+}
+
+// Checks a command line flag value against the values config.schema.json allows for the matching config field,
+// so a misspelled --formatter or --parser is caught the same way as one in a config file
+// An empty value means the flag wasn't set, and is always allowed
+func checkFlagValue(flag string, value string, def string) error {
+	if value == "" {
+		return nil
+	}
+	var root jsonSchema
+	err := json.Unmarshal(configSchemaJSON, &root)
+	if err != nil {
+		return err
+	}
+	schema, ok := root.Defs[def]
+	if !ok {
+		panic(fmt.Sprintf("config schema has no definition for %s", def))
+	}
+	options := enumOptions(schema.Enum)
+	if slices.Contains(options, value) {
+		return nil
+	}
+	suggestion := didYouMean(value, options)
+	if suggestion != "" {
+		return fmt.Errorf("unknown value %q for --%s, did you mean %q?", value, flag, suggestion)
+	}
+	return fmt.Errorf("unknown value %q for --%s, expected one of %s", value, flag, strings.Join(options, ", "))
+}
+
+// Returns the values of an enum to suggest in error messages, leaving out the empty string which stands for the default
+func enumOptions(enum []string) []string {
+	out := []string{}
+	for _, o := range enum {
+		if o != "" {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// Describes the type of a decoded JSON value for error messages
+func jsonKind(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "a list"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		return fmt.Sprintf("number %v", v)
+	}
+}
+
+// Returns the closest option to a misspelled key or value, or an empty string if none is close enough
+func didYouMean(s string, options []string) string {
+	best, best_dist := "", max(2, len(s)/3)+1
+	for _, o := range options {
+		d := editDistance(strings.ToLower(s), strings.ToLower(o))
+		if d < best_dist {
+			best, best_dist = o, d
+		}
+	}
+	return best
+}
+
+// Returns the Levenshtein distance between two strings
+func editDistance(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range ar {
+		curr[0] = i + 1
+		for j := range br {
+			cost := 1
+			if ar[i] == br[j] {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j+1]+1, curr[j]+1, prev[j]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
@@ -0,0 +1,483 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 10:12:37 AM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Reads a single file from inside a zip container, as used by xlsx and ods workbooks
+func readZipFile(r *zip.Reader, name string) ([]byte, error) {
+	name = strings.TrimPrefix(name, "/")
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+// Opens the file at the specified path as a zip container
+func zipFromFile(p string) (*zip.Reader, error) {
+	reader, err := ReaderFromFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(reader, reader.Size())
+}
+
+// Picks a sheet from a workbook, by name first and then by 0 based index
+// An empty selection returns the first sheet
+func selectSheet(names []string, s string) (int, error) {
+	if s == "" && len(names) > 0 {
+		return 0, nil
+	}
+	for i, name := range names {
+		if name == s {
+			return i, nil
+		}
+	}
+	i, err := strconv.Atoi(s)
+	if err == nil && i >= 0 && i < len(names) {
+		return i, nil
+	}
+	return 0, fmt.Errorf("sheet %q not found, available sheets are %v", s, names)
+}
+
+// Converts an A1 style cell reference into 0 based column and row indices
+func parseCellRef(r string) (x, y int, err error) {
+	r = strings.ToUpper(strings.ReplaceAll(r, "$", ""))
+	i := 0
+	for i < len(r) && r[i] >= 'A' && r[i] <= 'Z' {
+		x = x*26 + int(r[i]-'A'+1)
+		i++
+	}
+	y, err = strconv.Atoi(r[i:])
+	if i == 0 || err != nil || y < 1 {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", r)
+	}
+	return x - 1, y - 1, nil
+}
+
+// Pads all records to the same length so they form a rectangular grid
+func padRecords(records [][]string) [][]string {
+	width := 0
+	for _, row := range records {
+		width = max(width, len(row))
+	}
+	for i, row := range records {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		records[i] = row
+	}
+	return records
+}
+
+// Crops records to an A1 style range such as B3:H40
+// An empty range returns the records unchanged
+func cropRange(records [][]string, r string) ([][]string, error) {
+	if r == "" {
+		return records, nil
+	}
+	start, end, found := strings.Cut(r, ":")
+	if !found {
+		end = start
+	}
+	x0, y0, err := parseCellRef(start)
+	if err != nil {
+		return nil, err
+	}
+	x1, y1, err := parseCellRef(end)
+	if err != nil {
+		return nil, err
+	}
+	x0, x1 = min(x0, x1), max(x0, x1)
+	y0, y1 = min(y0, y1), max(y0, y1)
+
+	out := [][]string{}
+	for y := y0; y <= y1; y++ {
+		row := make([]string, x1-x0+1)
+		if y < len(records) {
+			for x := x0; x <= x1 && x < len(records[y]); x++ {
+				row[x-x0] = records[y][x]
+			}
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// Rich text is split into runs, each with its own text element
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	out := t.T
+	for _, run := range t.Runs {
+		out += run.T
+	}
+	return out
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R  string   `xml:"r,attr"`
+			T  string   `xml:"t,attr"`
+			V  string   `xml:"v"`
+			Is xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Reads the shared string table, which is absent from workbooks without any text cells
+func readSharedStrings(r *zip.Reader) ([]string, error) {
+	content, err := readZipFile(r, "xl/sharedStrings.xml")
+	if err != nil {
+		return []string{}, nil
+	}
+	sst := xlsxSharedStrings{}
+	err = xml.Unmarshal(content, &sst)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for _, item := range sst.Items {
+		out = append(out, item.String())
+	}
+	return out, nil
+}
+
+// Finds the location of the selected worksheet within the xlsx container
+func xlsxSheetPath(r *zip.Reader, s string) (string, error) {
+	content, err := readZipFile(r, "xl/workbook.xml")
+	if err != nil {
+		return "", err
+	}
+	wb := xlsxWorkbook{}
+	err = xml.Unmarshal(content, &wb)
+	if err != nil {
+		return "", err
+	}
+	names := []string{}
+	for _, sheet := range wb.Sheets {
+		names = append(names, sheet.Name)
+	}
+	i, err := selectSheet(names, s)
+	if err != nil {
+		return "", err
+	}
+
+	content, err = readZipFile(r, "xl/_rels/workbook.xml.rels")
+	if err != nil {
+		return "", err
+	}
+	rels := xlsxRelationships{}
+	err = xml.Unmarshal(content, &rels)
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[i].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return rel.Target, nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", fmt.Errorf("no worksheet found for sheet %q", names[i])
+}
+
+// Reads all cell values from the selected worksheet as a rectangular grid of strings
+func readXLSXRecords(p string, s string) ([][]string, error) {
+	r, err := zipFromFile(p)
+	if err != nil {
+		return nil, err
+	}
+	strs, err := readSharedStrings(r)
+	if err != nil {
+		return nil, err
+	}
+	sheetPath, err := xlsxSheetPath(r, s)
+	if err != nil {
+		return nil, err
+	}
+	content, err := readZipFile(r, sheetPath)
+	if err != nil {
+		return nil, err
+	}
+	ws := xlsxWorksheet{}
+	err = xml.Unmarshal(content, &ws)
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{}
+	for i, row := range ws.Rows {
+		y := i
+		if row.R > 0 {
+			y = row.R - 1
+		}
+		for len(records) <= y {
+			records = append(records, []string{})
+		}
+		for j, cell := range row.Cells {
+			x := j
+			if cell.R != "" {
+				x, _, err = parseCellRef(cell.R)
+				if err != nil {
+					return nil, err
+				}
+			}
+			for len(records[y]) <= x {
+				records[y] = append(records[y], "")
+			}
+			switch cell.T {
+			case "s":
+				k, err := strconv.Atoi(cell.V)
+				if err != nil || k < 0 || k >= len(strs) {
+					return nil, fmt.Errorf("invalid shared string index %q in cell %s", cell.V, cell.R)
+				}
+				records[y][x] = strs[k]
+			case "inlineStr":
+				records[y][x] = cell.Is.String()
+			default:
+				records[y][x] = cell.V
+			}
+		}
+	}
+	return padRecords(records), nil
+}
+
+type XLSXParser struct {
+	path string
+	// Sheet name or 0 based index, defaults to the first sheet
+	sheet string
+	// Optional A1 style range to read from the sheet, such as B3:H40
+	cell_range string
+}
+
+// Reads XLSX workbook sheet into a table
+func (p *XLSXParser) parse() (LabeledTable, error) {
+	records, err := readXLSXRecords(p.path, p.sheet)
+	if err != nil {
+		return LabeledTable{}, err
+	}
+	records, err = cropRange(records, p.cell_range)
+	if err != nil {
+		return LabeledTable{}, err
+	}
+	return loadTable(records)
+}
+
+const (
+	odsTableNS = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsTextNS  = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+// Represents a single table:table element from an ods document
+type odsSheet struct {
+	name    string
+	records [][]string
+}
+
+// Returns the value of a namespaced attribute on an ods element
+func odsAttr(e xml.StartElement, ns string, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Space == ns && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Returns the repeat count from a number-*-repeated attribute, defaulting to 1
+func odsRepeats(e xml.StartElement, ns string, name string) int {
+	n, err := strconv.Atoi(odsAttr(e, ns, name))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// Reads all sheets from the content.xml of an ods document
+// Repeated rows and cells are expanded, except for trailing empty ones which spreadsheet applications use to pad out the sheet
+func readODSSheets(p string) ([]odsSheet, error) {
+	r, err := zipFromFile(p)
+	if err != nil {
+		return nil, err
+	}
+	content, err := readZipFile(r, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := []odsSheet{}
+	row := []string{}
+	cell := strings.Builder{}
+	var rowRepeats, cellRepeats, emptyRows, emptyCells, paragraphs, depth int
+	inCell := false
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			switch tok.Name.Space + " " + tok.Name.Local {
+			case odsTableNS + " table":
+				sheets = append(sheets, odsSheet{name: odsAttr(tok, odsTableNS, "name"), records: [][]string{}})
+				emptyRows = 0
+			case odsTableNS + " table-row":
+				row = []string{}
+				emptyCells = 0
+				rowRepeats = odsRepeats(tok, odsTableNS, "number-rows-repeated")
+			case odsTableNS + " table-cell", odsTableNS + " covered-table-cell":
+				inCell = true
+				cell.Reset()
+				paragraphs = 0
+				cellRepeats = odsRepeats(tok, odsTableNS, "number-columns-repeated")
+			case odsTextNS + " p", odsTextNS + " h":
+				if inCell && paragraphs > 0 {
+					cell.WriteString("\n")
+				}
+				paragraphs++
+				depth++
+			case odsTextNS + " s":
+				if inCell {
+					cell.WriteString(strings.Repeat(" ", odsRepeats(tok, odsTextNS, "c")))
+				}
+			case odsTextNS + " tab":
+				if inCell {
+					cell.WriteString("\t")
+				}
+			case odsTextNS + " line-break":
+				if inCell {
+					cell.WriteString("\n")
+				}
+			}
+		case xml.CharData:
+			if inCell && depth > 0 {
+				cell.Write(tok)
+			}
+		case xml.EndElement:
+			switch tok.Name.Space + " " + tok.Name.Local {
+			case odsTextNS + " p", odsTextNS + " h":
+				depth--
+			case odsTableNS + " table-cell", odsTableNS + " covered-table-cell":
+				inCell = false
+				if cell.Len() == 0 {
+					emptyCells += cellRepeats
+					continue
+				}
+				for ; emptyCells > 0; emptyCells-- {
+					row = append(row, "")
+				}
+				for range cellRepeats {
+					row = append(row, cell.String())
+				}
+			case odsTableNS + " table-row":
+				if len(sheets) == 0 {
+					continue
+				}
+				sheet := &sheets[len(sheets)-1]
+				if len(row) == 0 {
+					emptyRows += rowRepeats
+					continue
+				}
+				for ; emptyRows > 0; emptyRows-- {
+					sheet.records = append(sheet.records, []string{})
+				}
+				for range rowRepeats {
+					sheet.records = append(sheet.records, slices.Clone(row))
+				}
+			}
+		}
+	}
+	return sheets, nil
+}
+
+// Reads all cell values from the selected ods sheet as a rectangular grid of strings
+func readODSRecords(p string, s string) ([][]string, error) {
+	sheets, err := readODSSheets(p)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, sheet := range sheets {
+		names = append(names, sheet.name)
+	}
+	i, err := selectSheet(names, s)
+	if err != nil {
+		return nil, err
+	}
+	return padRecords(sheets[i].records), nil
+}
+
+type ODSParser struct {
+	path string
+	// Sheet name or 0 based index, defaults to the first sheet
+	sheet string
+	// Optional A1 style range to read from the sheet, such as B3:H40
+	cell_range string
+}
+
+// Reads ODS spreadsheet sheet into a table
+func (p *ODSParser) parse() (LabeledTable, error) {
+	records, err := readODSRecords(p.path, p.sheet)
+	if err != nil {
+		return LabeledTable{}, err
+	}
+	records, err = cropRange(records, p.cell_range)
+	if err != nil {
+		return LabeledTable{}, err
+	}
+	return loadTable(records)
+}
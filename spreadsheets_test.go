@@ -0,0 +1,154 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 10:12:37 AM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+func TestParseCellRef(t *testing.T) {
+	table := []struct {
+		ref   string
+		exp_x int
+		exp_y int
+		err   bool
+	}{
+		{"A1", 0, 0, false},
+		{"B3", 1, 2, false},
+		{"h40", 7, 39, false},
+		{"$AA$10", 26, 9, false},
+		{"A0", 0, 0, true},
+		{"12", 0, 0, true},
+		{"B", 0, 0, true},
+	}
+
+	for _, test := range table {
+		x, y, err := parseCellRef(test.ref)
+		if (err != nil) != test.err {
+			t.Errorf("parseCellRef(%v) returned error %v, expected error %v", test.ref, err, test.err)
+		}
+		if x != test.exp_x || y != test.exp_y {
+			t.Errorf("parseCellRef(%v) = %v, %v, expected %v, %v", test.ref, x, y, test.exp_x, test.exp_y)
+		}
+	}
+}
+
+func TestSelectSheet(t *testing.T) {
+	names := []string{"Sheet1", "2", "offset"}
+	table := []struct {
+		sheet string
+		exp   int
+		err   bool
+	}{
+		{"", 0, false},
+		{"offset", 2, false},
+		{"0", 0, false},
+		{"2", 1, false},
+		{"3", 0, true},
+		{"missing", 0, true},
+	}
+
+	for _, test := range table {
+		res, err := selectSheet(names, test.sheet)
+		if (err != nil) != test.err {
+			t.Errorf("selectSheet(%v) returned error %v, expected error %v", test.sheet, err, test.err)
+		}
+		if res != test.exp {
+			t.Errorf("selectSheet(%v) = %v, expected %v", test.sheet, res, test.exp)
+		}
+	}
+}
+
+func TestCropRange(t *testing.T) {
+	records := [][]string{
+		{"a1", "b1", "c1"},
+		{"a2", "b2", "c2"},
+		{"a3", "b3", "c3"},
+	}
+	table := []struct {
+		r   string
+		exp [][]string
+	}{
+		{"", records},
+		{"B2:C3", [][]string{{"b2", "c2"}, {"b3", "c3"}}},
+		{"C3:B2", [][]string{{"b2", "c2"}, {"b3", "c3"}}},
+		{"A3", [][]string{{"a3"}}},
+		{"C3:D4", [][]string{{"c3", ""}, {"", ""}}},
+	}
+
+	for _, test := range table {
+		res, err := cropRange(records, test.r)
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("cropRange(records, %v) = %v, expected %v", test.r, res, test.exp)
+		}
+	}
+}
+
+func TestXLSXParser(t *testing.T) {
+	df1, _, _ := reference_dataframes()
+	table := []struct {
+		f   FileParser
+		exp dataframe.DataFrame
+	}{
+		{&XLSXParser{"data/test1.xlsx", "", ""}, df1},
+		{&XLSXParser{"data/test1.xlsx", "Sheet1", "A1:D5"}, df1},
+		{&XLSXParser{"data/test1.xlsx", "offset", "B3:E7"}, df1},
+		{&XLSXParser{"data/test1.xlsx", "1", "B3:E7"}, df1},
+	}
+
+	for _, test := range table {
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("%v.parse() = %v, expected %v", test.f, res.df, test.exp)
+		}
+	}
+}
+
+func TestODSParser(t *testing.T) {
+	df1, _, df3 := reference_dataframes()
+	table := []struct {
+		f   FileParser
+		exp dataframe.DataFrame
+	}{
+		{&ODSParser{"data/test1.ods", "", ""}, df1},
+		{&ODSParser{"data/test1.ods", "Sheet1", "A1:D5"}, df1},
+		{&ODSParser{"data/test1.ods", "offset", "B3:E7"}, df1},
+		{&ODSParser{"data/test1.ods", "1", "B3:E7"}, df1},
+		{&ODSParser{"data/test1.ods", "repeated", ""}, df3},
+	}
+
+	for _, test := range table {
+		res, err := test.f.parse()
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+		if fmt.Sprint(res.df) != fmt.Sprint(test.exp) {
+			t.Errorf("%v.parse() = %v, expected %v", test.f, res.df, test.exp)
+		}
+	}
+}
+
+func TestReadODSRecords(t *testing.T) {
+	res, err := readODSRecords("data/test1.ods", "offset")
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if len(res) != 8 || len(res[0]) != 7 {
+		t.Errorf("readODSRecords(data/test1.ods, offset) has dims %v x %v, expected 8 x 7", len(res), len(res[0]))
+	}
+	if res[7][2] != "foot  note" {
+		t.Errorf("readODSRecords(data/test1.ods, offset)[7][2] = %q, expected %q", res[7][2], "foot  note")
+	}
+}
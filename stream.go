@@ -0,0 +1,299 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 09:12:26 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Builds DataValues for a table read one row at a time, keeping only the column headers between rows
+// Cells get the same headers NewTableData would give them, but aren't typed since no streaming formatter uses types
+type rowCellBuilder struct {
+	// Index of the next row
+	y int
+	// Number of leading columns counted as row headers
+	x_heads int
+	// Number of leading rows counted as column headers
+	y_heads int
+	// If header cells should be reformatted alongside data cells
+	keep_heads bool
+	// Column header values collected from the header rows
+	columns [][]string
+	// If blank header cells should be filled with the header value before them
+	fill bool
+	// Header rows as they were read, before filling
+	raw_heads [][]string
+	// Values carried down each row header column
+	row_fills []levelFill
+	// How empty and null cells are formatted
+	empty emptyPolicy
+	// Number of empty cells left out of the rows built so far
+	skipped int
+}
+
+// Fills blank header cells in the next row the same way as TableData.fillHeads
+func (b *rowCellBuilder) fillRow(y int, row []string) []string {
+	out := slices.Clone(row)
+	if y < b.y_heads {
+		b.raw_heads = append(b.raw_heads, row)
+		fill := levelFill{}
+		for x := b.x_heads; x < len(row); x++ {
+			group := false
+			for _, raw := range b.raw_heads[:y] {
+				group = group || (x < len(raw) && raw[x] != "")
+			}
+			out[x] = fill.next(row[x], group)
+		}
+		return out
+	}
+	if b.row_fills == nil {
+		b.row_fills = make([]levelFill, b.x_heads)
+	}
+	group := false
+	for x := 0; x < b.x_heads && x < len(row); x++ {
+		out[x] = b.row_fills[x].next(row[x], group)
+		group = group || row[x] != ""
+	}
+	return out
+}
+
+// Returns the cells of the next row which formatters should turn into statements
+func (b *rowCellBuilder) cells(row []string) []DataValue {
+	y := b.y
+	b.y++
+	if b.fill {
+		row = b.fillRow(y, row)
+	}
+	x_head := []string{}
+	out := []DataValue{}
+	for x, val := range row {
+		if x < b.x_heads {
+			x_head = append(x_head, val)
+		}
+		for len(b.columns) <= x {
+			b.columns = append(b.columns, nil)
+		}
+		if y < b.y_heads {
+			b.columns[x] = append(b.columns[x], val)
+		}
+		if !b.keep_heads && (x < b.x_heads || y < b.y_heads) {
+			continue
+		}
+		out = append(out, DataValue{x: x, y: y, x_head: x_head, y_head: b.columns[x], val: val})
+	}
+	out, skipped := b.empty.apply(out)
+	b.skipped += skipped
+	return out
+}
+
+// Writes statements to an output as they are produced, separated the same way as writeOutput
+// Output files are only created once there's something to write, or the file finishes successfully
+type statementWriter struct {
+	path string
+	file io.WriteCloser
+	buf  *bufio.Writer
+	n    int
+}
+
+func (s *statementWriter) open() error {
+	if s.buf != nil {
+		return nil
+	}
+	if s.path == StdStream {
+		s.buf = bufio.NewWriter(os.Stdout)
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err != nil {
+		return err
+	}
+	s.file, err = os.Create(s.path)
+	if err != nil {
+		return err
+	}
+	s.buf = bufio.NewWriter(s.file)
+	return nil
+}
+
+// Writes a single statement
+func (s *statementWriter) write(str string) error {
+	err := s.open()
+	if err != nil {
+		return err
+	}
+	if s.n > 0 {
+		s.buf.WriteByte('\n')
+	}
+	s.n++
+	_, err = s.buf.WriteString(str)
+	return err
+}
+
+// Flushes written statements, ending stdout output with a newline
+func (s *statementWriter) close() error {
+	err := s.open()
+	if err != nil {
+		return err
+	}
+	if s.path == StdStream {
+		s.buf.WriteByte('\n')
+	}
+	err = s.buf.Flush()
+	if s.file != nil {
+		return errors.Join(err, s.file.Close())
+	}
+	return err
+}
+
+// Reads an input file one row at a time, writing the statements for each row as soon as they are formatted
+// Only row parsers and cell formatters can stream, and statements must stay in table order and tables can't be transformed
+// or transposed, since sorting and reshaping need the whole table
+// With more than one formatter, the file is streamed once for each formatter in turn, so stdin is read into memory first
+// Returns the number of statements written and the number of empty cells skipped
+func streamFile(c ConfigFields, f FormatFields, out *statementWriter, prefix string, w io.Writer) (int, int, error) {
+	c, err := resolveParser(c, w)
+	if err != nil {
+		return 0, 0, err
+	}
+	set_parser, err := SetParser(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	parser, ok := set_parser.(RowParser)
+	if !ok {
+		return 0, 0, fmt.Errorf("parser %s can't be streamed, only CSV, TSV, and JSONLines can", c.Parser)
+	}
+	formatters := []CellFormatter{}
+	for _, name := range c.FormatterNames() {
+		set_formatter, err := SetFormatter(TableData{}, name, f)
+		if err != nil {
+			return 0, 0, err
+		}
+		formatter, ok := set_formatter.(CellFormatter)
+		if !ok {
+			return 0, 0, fmt.Errorf("formatter %s can't be streamed, only the Coord, NamedRow, NamedCol, RowKeyVal, and RowVal formatters can", name)
+		}
+		formatters = append(formatters, formatter)
+	}
+	if f.Sort != "" && f.Sort != SortPosition {
+		return 0, 0, fmt.Errorf("sort %q can't be streamed, only %q can", f.Sort, SortPosition)
+	}
+	if c.Transform.Type != "" {
+		return 0, 0, fmt.Errorf("transform %q can't be streamed, since it needs the whole table", c.Transform.Type)
+	}
+	// Types aren't used when streaming, but are still checked so a config doesn't only fail without --stream
+	err = checkTypes(c.Types)
+	if err != nil {
+		return 0, 0, err
+	}
+	if c.Transpose {
+		return 0, 0, fmt.Errorf("transposed tables can't be streamed, since transposing needs the whole table")
+	}
+	if c.InFile == StdStream && len(formatters) > 1 {
+		_, err = readFile(c.InFile)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	y, x := c.HeaderCounts(LabeledTable{})
+	fmt.Fprintf(w, "Streaming table from %s using %d column header rows and %d row header columns\n", c.InFile, y, x)
+	n, skipped := 0, 0
+	for i, formatter := range formatters {
+		builder := rowCellBuilder{x_heads: x, y_heads: y, keep_heads: c.IncludeHeaders, fill: c.FillHeaders, empty: newEmptyPolicy(c)}
+		err = parser.parseRows(func(row []string) error {
+			for i := range row {
+				s, err := normalizeText(row[i], c.Normalize)
+				if err != nil {
+					return err
+				}
+				row[i] = s
+			}
+			for _, str := range formatter.formatCells(builder.cells(row), f) {
+				err := out.write(prefix + str)
+				if err != nil {
+					return err
+				}
+				n++
+			}
+			return nil
+		})
+		// Each formatter reads the same cells, so skipped cells are only counted once
+		if i == 0 {
+			skipped = builder.skipped
+		}
+		if err != nil {
+			break
+		}
+	}
+	fmt.Fprintf(w, "%d statements streamed using %v\n", n, strings.Join(c.FormatterNames(), ", "))
+	if skipped > 0 {
+		fmt.Fprintf(w, "%d empty cells skipped\n", skipped)
+	}
+	return n, skipped, err
+}
+
+// Runs the streaming pipeline for every input file matched by the config, one file at a time
+// Outputs follow the same naming and combining rules as RunBatch, but statements are written as they are produced,
+// so a file that fails part way through keeps the statements written before the failure
+func RunStream(c ConfigFields, f FormatFields) ([]FileResult, error) {
+	if c.OutFile == "" {
+		c.OutFile = StdStream
+	}
+	inputs, err := ExpandInputs(c.InFile, c.Parser)
+	if err != nil {
+		return nil, err
+	}
+	err = checkOutputPaths(c.OutFile, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := isOutputPattern(c.OutFile)
+	combined := &statementWriter{path: c.OutFile}
+	results := []FileResult{}
+	succeeded := 0
+	for _, in := range inputs {
+		file_config := c
+		file_config.InFile = in
+		result := FileResult{InFile: in, OutFile: c.OutFile}
+		out, prefix := combined, ""
+		if pattern {
+			result.OutFile = outputPath(c.OutFile, in)
+			out = &statementWriter{path: result.OutFile}
+		} else if len(inputs) > 1 {
+			prefix = in + ": "
+		}
+		result.Statements, result.Skipped, result.Err = streamFile(file_config, f, out, prefix, os.Stderr)
+		if pattern && (result.Err == nil || out.n > 0) {
+			result.Err = errors.Join(result.Err, out.close())
+		}
+		if result.Err == nil {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	if !pattern && (succeeded > 0 || combined.n > 0) {
+		err = combined.close()
+		if err != nil {
+			return results, err
+		}
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			fmt.Fprintf(os.Stderr, "Output written to %v\n", r.OutFile)
+		}
+	}
+	return results, nil
+}
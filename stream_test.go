@@ -0,0 +1,205 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 09:12:26 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunStream(t *testing.T) {
+	fields := FormatFields{Delim: "/", Link: "for", Eq: "is", ValLabel: "price", XLabel: "row", YLabel: "column", Pre: "all"}
+	formatters := []string{
+		"UnnamedCoordFormatter1",
+		"UnnamedCoordFormatter2",
+		"NamedCoordFormatter1",
+		"NamedCoordFormatter2",
+		"NamedRowFormatter",
+		"NamedColFormatter",
+		"UnnamedRowKeyValFormatter",
+		"NamedRowKeyValFormatter",
+		"RowValFormatter",
+	}
+	configs := []ConfigFields{
+		{InFile: "data/test1.csv", Parser: "CSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(1)},
+		{InFile: "data/test2.csv", Parser: "CSV", NRowHeaders: ref_int(2), NColHeaders: ref_int(2)},
+		{InFile: "data/test1.tsv", Parser: "TSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(0), IncludeHeaders: true},
+		{InFile: "data/test1.jsonl", Parser: "JSONLines", NRowHeaders: ref_int(1), NColHeaders: ref_int(1)},
+		{InFile: "data/test_keys_missing.jsonl", Parser: "JSONLines", NRowHeaders: ref_int(1), NColHeaders: ref_int(1)},
+		{InFile: "data/test_pipe.csv", Parser: ParserAuto, NRowHeaders: ref_int(1), NColHeaders: ref_int(1), CSV: CSVOptions{Quote: "'", TrimLeadingSpace: true}},
+		{InFile: "data/test_levels.csv", Parser: "CSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(2)},
+		{InFile: "data/test_grouped.csv", Parser: "CSV", NRowHeaders: ref_int(2), NColHeaders: ref_int(2), FillHeaders: true},
+		{InFile: "data/test_nulls.csv", Parser: "CSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(1), EmptyCells: EmptySkip},
+		{InFile: "data/test_nulls.csv", Parser: "CSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(1), EmptyCells: EmptySubstitute},
+		{InFile: "data/test_cp1252.csv", Parser: "CSV", NRowHeaders: ref_int(1), NColHeaders: ref_int(1), Normalize: []string{NormalizeQuotes, NormalizeSpaces}},
+	}
+
+	dir := t.TempDir()
+	for _, config := range configs {
+		for _, formatter := range formatters {
+			if config.InFile == "data/test2.csv" && (strings.HasSuffix(formatter, "RowKeyValFormatter") || formatter == "RowValFormatter") {
+				// Rows sharing a blank row header are grouped across the whole table, but only within a row when streaming
+				continue
+			}
+			config.Formatter = formatter
+			config.OutFile = filepath.Join(dir, "table.txt")
+			batch, err := RunBatch(config, fields)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			exp, err := os.ReadFile(config.OutFile)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			config.OutFile = filepath.Join(dir, "stream.txt")
+			results, err := RunStream(config, fields)
+			if err != nil || results[0].Err != nil {
+				t.Fatalf("RunStream(%v, %v) returned errors %v, %v", config.InFile, formatter, err, results[0].Err)
+			}
+			res, err := os.ReadFile(config.OutFile)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			if string(res) != string(exp) {
+				t.Errorf("RunStream(%v, %v) wrote %q, expected %q", config.InFile, formatter, res, exp)
+			}
+			if results[0].Skipped != batch[0].Skipped {
+				t.Errorf("RunStream(%v, %v) skipped %v cells, expected %v", config.InFile, formatter, results[0].Skipped, batch[0].Skipped)
+			}
+		}
+	}
+}
+
+func TestRunStreamJSONLinesKeys(t *testing.T) {
+	fields := FormatFields{Link: "for", Eq: "is", XLabel: "a"}
+	config := ConfigFields{InFile: "data/test_keys_added.jsonl", Parser: "JSONLines", Formatter: "NamedRowFormatter", NRowHeaders: ref_int(1), NColHeaders: ref_int(1)}
+	dir := t.TempDir()
+
+	config.OutFile = filepath.Join(dir, "table.txt")
+	_, err := RunBatch(config, fields)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	res, err := os.ReadFile(config.OutFile)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !strings.Contains(string(res), "c is 5") {
+		t.Errorf("RunBatch(%v) wrote %q, expected it to contain %q", config.InFile, res, "c is 5")
+	}
+
+	config.OutFile = filepath.Join(dir, "stream.txt")
+	results, err := RunStream(config, fields)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if results[0].Err == nil || !strings.Contains(results[0].Err.Error(), `line 2 of data/test_keys_added.jsonl has key "c"`) {
+		t.Errorf("RunStream(%v) returned error %v, expected an error for the new key on line 2", config.InFile, results[0].Err)
+	}
+}
+
+func TestRunStreamErrors(t *testing.T) {
+	table := []struct {
+		config ConfigFields
+		fields FormatFields
+	}{
+		{ConfigFields{InFile: "data/test1.html", Parser: "HTML", Formatter: "NamedRowFormatter"}, FormatFields{}},
+		{ConfigFields{InFile: "data/test1.csv", Parser: "CSV", Formatter: "NamedColKeyValFormatter"}, FormatFields{}},
+		{ConfigFields{InFile: "data/test1.csv", Parser: "CSV", Formatter: "NamedRowFormatter"}, FormatFields{Sort: SortValue}},
+		{ConfigFields{InFile: "data/test_malformed.jsonl", Parser: "JSONLines", Formatter: "NamedRowFormatter"}, FormatFields{}},
+		{ConfigFields{InFile: "data/test_keys_added.jsonl", Parser: "JSONLines", Formatter: "NamedRowFormatter"}, FormatFields{}},
+		{ConfigFields{InFile: "data/test_wide.csv", Parser: "CSV", Formatter: "NamedRowFormatter", Transform: TransformOptions{Type: TransformMelt}}, FormatFields{}},
+		{ConfigFields{InFile: "data/test_specs.csv", Parser: "CSV", Formatter: "NamedRowFormatter", Transpose: true}, FormatFields{}},
+		{ConfigFields{InFile: "data/test1.csv", Parser: "CSV", Formatter: "NamedRowFormatter", Types: map[string]string{"col1": "money"}}, FormatFields{}},
+	}
+
+	for _, test := range table {
+		test.config.OutFile = filepath.Join(t.TempDir(), "{stem}.txt")
+		results, err := RunStream(test.config, test.fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if results[0].Err == nil {
+			t.Errorf("RunStream(%v, %v) returned no error", test.config.InFile, test.config.Formatter)
+		}
+	}
+}
+
+// Writes a CSV file with the given number of data rows to a temporary directory
+func writeBenchmarkCSV(b *testing.B, rows int) string {
+	p := filepath.Join(b.TempDir(), fmt.Sprintf("bench%d.csv", rows))
+	var sb strings.Builder
+	sb.WriteString("id,name,size,price,stock\n")
+	for i := range rows {
+		fmt.Fprintf(&sb, "row%d,item %d,%d,$%d.99,%t\n", i, i%97, i%5, i%50, i%2 == 0)
+	}
+	err := os.WriteFile(p, []byte(sb.String()), 0o644)
+	if err != nil {
+		b.Fatalf("%v", err)
+	}
+	return p
+}
+
+// Samples the heap while fn runs, returning the peak heap in use in MB
+func peakHeap(fn func()) float64 {
+	runtime.GC()
+	done := make(chan struct{})
+	peak := make(chan uint64)
+	go func() {
+		var m runtime.MemStats
+		var max_heap uint64
+		for {
+			runtime.ReadMemStats(&m)
+			max_heap = max(max_heap, m.HeapInuse)
+			select {
+			case <-done:
+				peak <- max_heap
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+	fn()
+	close(done)
+	return float64(<-peak) / (1 << 20)
+}
+
+// Compares the peak heap of the streaming and whole table pipelines as the input grows
+// The streaming peak should stay flat, while the whole table peak grows with the number of rows
+func BenchmarkRunStream(b *testing.B) {
+	fields := FormatFields{Link: "for", Eq: "is", ValLabel: "value"}
+	for _, rows := range []int{1000, 10000, 100000} {
+		in := writeBenchmarkCSV(b, rows)
+		config := ConfigFields{InFile: in, OutFile: filepath.Join(b.TempDir(), "out.txt"), Parser: "CSV", Formatter: "UnnamedCoordFormatter1", NRowHeaders: ref_int(1), NColHeaders: ref_int(1)}
+		for _, stream := range []bool{true, false} {
+			name := fmt.Sprintf("rows=%d/table", rows)
+			run := RunBatch
+			if stream {
+				name = fmt.Sprintf("rows=%d/stream", rows)
+				run = RunStream
+			}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				peak := 0.0
+				for range b.N {
+					peak = max(peak, peakHeap(func() {
+						_, err := run(config, fields)
+						if err != nil {
+							b.Fatalf("%v", err)
+						}
+					}))
+				}
+				b.ReportMetric(peak, "peak-heap-MB")
+			})
+		}
+	}
+}
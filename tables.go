@@ -6,6 +6,10 @@
 package main
 
 import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/go-gota/gota/dataframe"
@@ -23,6 +27,8 @@ type DataValue struct {
 	y_head []string
 	// Value of the cell at [x, y]
 	val string
+	// Value of the cell parsed according to its inferred or configured type
+	typed TypedValue
 }
 
 // Combines the provided number of headers into a single string for a DataValue
@@ -30,6 +36,55 @@ func (v DataValue) JoinHeaders(d string) (string, string) {
 	return strings.Join(v.x_head, d), strings.Join(v.y_head, d)
 }
 
+// A single level of a row or column header, such as the year in a column header made up of year and quarter rows
+type HeaderLevel struct {
+	// Label naming the level, such as year, which may be empty
+	Label string
+	// Value of the header at this level, such as 2024
+	Value string
+}
+
+// Pairs each level of a header with its label, outermost level first
+// Levels beyond the end of the labels are left unlabeled
+func headerLevels(head []string, labels []string) []HeaderLevel {
+	out := []HeaderLevel{}
+	for i, val := range head {
+		level := HeaderLevel{Value: val}
+		if i < len(labels) {
+			level.Label = labels[i]
+		}
+		out = append(out, level)
+	}
+	return out
+}
+
+// Returns the levels of the row and column headers for a DataValue, labeled with the given labels for each axis
+func (v DataValue) HeaderLevels(x_labels []string, y_labels []string) ([]HeaderLevel, []HeaderLevel) {
+	return headerLevels(v.x_head, x_labels), headerLevels(v.y_head, y_labels)
+}
+
+// Describes a header for named formatters, either as its label and value joined with delim, such as "year is 2024 / Q1",
+// or when level labels are given as each labeled level in turn, such as "year is 2024 and quarter is Q1"
+func describeHeader(head []string, label string, levels []string, ff FormatFields) string {
+	if len(levels) == 0 {
+		return fmt.Sprintf("%s %s %s", label, ff.Eq, strings.Join(head, ff.Delim))
+	}
+	parts := []string{}
+	for _, level := range headerLevels(head, levels) {
+		if level.Label == "" {
+			parts = append(parts, level.Value)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", level.Label, ff.Eq, level.Value))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// Describes the row and column headers of a DataValue for named formatters, using x_label and y_label or the level labels
+func (v DataValue) DescribeHeaders(ff FormatFields) (string, string) {
+	return describeHeader(v.x_head, ff.XLabel, ff.XLevels, ff), describeHeader(v.y_head, ff.YLabel, ff.YLevels, ff)
+}
+
 // Represents all data in a table
 type TableData struct {
 	// All DataValues in the table
@@ -42,18 +97,135 @@ type TableData struct {
 	x_dim int
 	// Size of the y axis, or the number of rows
 	y_dim int
+	// Number of leading columns counted as row headers
+	x_heads int
+	// Number of leading rows counted as column headers
+	y_heads int
+	// If header cells should be reformatted alongside data cells
+	keep_heads bool
+	// How empty and null cells are formatted
+	empty emptyPolicy
 }
 
-// Pulls dimensions of dataframe to DataTable
-func (t *TableData) populateDims(df dataframe.DataFrame) {
-	t.y_dim, t.x_dim = df.Dims()
+// Policies for cells which are blank or hold a null value such as NA
+const (
+	// Formats empty cells like any other
+	EmptyKeep = "keep"
+	// Leaves empty cells out, dropping their statement, or their item from statements listing several values
+	EmptySkip = "skip"
+	// Formats empty cells with a phrase such as "not available" in place of their value
+	EmptySubstitute = "substitute"
+)
+
+// Phrase formatted in place of empty cells when substituting, unless the config gives its own
+const DefaultEmptyText = "not available"
+
+// Values treated as null unless the config gives its own, alongside blank cells
+var DefaultNullValues = []string{"NA", "N/A", "null", "-", "—"}
+
+// Decides how cells which are blank or hold a null value are formatted
+type emptyPolicy struct {
+	// One of EmptyKeep, EmptySkip, or EmptySubstitute, keeping empty cells when unset
+	mode string
+	// Phrase formatted in place of empty cells when substituting
+	text string
+	// Values treated as null
+	nulls []string
+}
+
+// Reads the empty cell policy from a config, filling in the default phrase and null values
+func newEmptyPolicy(c ConfigFields) emptyPolicy {
+	p := emptyPolicy{c.EmptyCells, c.EmptyText, c.NullValues}
+	if p.text == "" {
+		p.text = DefaultEmptyText
+	}
+	if p.nulls == nil {
+		p.nulls = DefaultNullValues
+	}
+	return p
+}
+
+// Checks if a cell value is blank or one of the null values, ignoring case and surrounding whitespace
+func (p emptyPolicy) isEmpty(val string) bool {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return true
+	}
+	for _, null := range p.nulls {
+		if strings.EqualFold(val, strings.TrimSpace(null)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Applies the policy to cells about to be formatted, returning the cells to format and the number of empty cells skipped
+func (p emptyPolicy) apply(cells []DataValue) ([]DataValue, int) {
+	if p.mode != EmptySkip && p.mode != EmptySubstitute {
+		return cells, 0
+	}
+	out := []DataValue{}
+	skipped := 0
+	for _, cell := range cells {
+		switch {
+		case !p.isEmpty(cell.val):
+		case p.mode == EmptySkip:
+			skipped++
+			continue
+		default:
+			cell.val = p.text
+			cell.typed = InferType(p.text)
+		}
+		out = append(out, cell)
+	}
+	return out, skipped
+}
+
+// Checks if a DataValue sits in the header rows or columns of the table
+func (t TableData) isHead(v DataValue) bool {
+	return v.x < t.x_heads || v.y < t.y_heads
+}
+
+// Returns the cells that formatters should turn into statements
+// Header cells are left out unless the table is set to keep them, and empty cells are handled by the table's empty cell policy
+func (t TableData) dataCells() []DataValue {
+	out, _ := t.empty.apply(t.valueCells())
+	return out
+}
+
+// Returns the number of cells the table's empty cell policy leaves out of statements
+func (t TableData) skippedCells() int {
+	_, n := t.empty.apply(t.valueCells())
+	return n
+}
+
+// Returns the cells holding values, leaving out header cells unless the table is set to keep them
+func (t TableData) valueCells() []DataValue {
+	if t.keep_heads {
+		return t.cells
+	}
+	out := []DataValue{}
+	for _, cell := range t.cells {
+		if !t.isHead(cell) {
+			out = append(out, cell)
+		}
+	}
+	return out
+}
+
+// Pulls dimensions of the table records to DataTable, counting rows after the header row as dataframes do
+func (t *TableData) populateDims(records [][]string) {
+	if len(records) == 0 {
+		return
+	}
+	t.y_dim, t.x_dim = len(records)-1, len(records[0])
 }
 
 // Breaks table into individual cells and stores in TableData.cells
-func (t *TableData) populateCells(df dataframe.DataFrame) {
-	for y, row := range df.Records() {
+func (t *TableData) populateCells(records [][]string) {
+	for y, row := range records {
 		for x, cell := range row {
-			t.cells = append(t.cells, DataValue{x: x, y: y, val: cell})
+			t.cells = append(t.cells, DataValue{x: x, y: y, val: cell, typed: InferType(cell)})
 		}
 	}
 }
@@ -84,13 +256,143 @@ func (t *TableData) populateColumns(n int) {
 	}
 }
 
+// Parses the cells of the specified columns as the configured types, overriding inferred types
+// Columns are identified by name, meaning the value in the first row, or by 0 based index
+// Cells that don't match their column's type, such as header cells, are kept as TypeString
+// Returns an error without changing any cells if a type isn't a known ValueType
+func (t *TableData) applyTypes(types map[string]string) error {
+	err := checkTypes(types)
+	if err != nil || len(types) == 0 {
+		return err
+	}
+	names := map[int]string{}
+	for _, cell := range t.cells {
+		if cell.y == 0 {
+			names[cell.x] = cell.val
+		}
+	}
+	for i, cell := range t.cells {
+		kind, ok := types[names[cell.x]]
+		if !ok {
+			kind, ok = types[strconv.Itoa(cell.x)]
+		}
+		if !ok {
+			continue
+		}
+		typed, err := ParseAs(cell.val, ValueType(kind))
+		if err != nil {
+			typed = TypedValue{TypeString, cell.val, ""}
+		}
+		t.cells[i].typed = typed
+	}
+	return nil
+}
+
+// Swaps the rows and columns of the table, along with its row and column header counts
+// Cells keep their values and types, and are reordered to read across each row of the transposed table
+func (t *TableData) transpose() {
+	for i, cell := range t.cells {
+		t.cells[i].x, t.cells[i].y = cell.y, cell.x
+	}
+	slices.SortStableFunc(t.cells, func(a, b DataValue) int {
+		return cmp.Or(cmp.Compare(a.y, b.y), cmp.Compare(a.x, b.x))
+	})
+	// y_dim leaves out the first row, which holds the column names
+	t.x_dim, t.y_dim = t.y_dim+1, t.x_dim-1
+	t.x_heads, t.y_heads = t.y_heads, t.x_heads
+	t.populateRows(t.x_heads)
+	t.populateColumns(t.y_heads)
+}
+
+// Carries the last non-blank value of a header level forward into the blank values after it
+type levelFill struct {
+	carry string
+}
+
+// Returns the filled value for the next position along a header level
+// group reports if an outer header level starts a new group at this position, so nothing is carried over from the group before
+func (f *levelFill) next(val string, group bool) string {
+	if group {
+		f.carry = ""
+	}
+	if val == "" {
+		return f.carry
+	}
+	f.carry = val
+	return val
+}
+
+// Fills blank header cells with the header value before them, down each row header column and right along each column header row
+// Cells in the corner where row and column headers meet are left as they are
+func (t *TableData) fillHeads() {
+	grid := map[[2]int]int{}
+	for i, cell := range t.cells {
+		grid[[2]int{cell.x, cell.y}] = i
+	}
+	fill := func(fills []levelFill, x int, y int, level int, group bool) bool {
+		i, ok := grid[[2]int{x, y}]
+		if !ok {
+			return group
+		}
+		val := t.cells[i].val
+		filled := fills[level].next(val, group)
+		if filled != val {
+			t.cells[i].val = filled
+			t.cells[i].typed = InferType(filled)
+		}
+		return group || val != ""
+	}
+
+	fills := make([]levelFill, t.x_heads)
+	for y := t.y_heads; y <= t.y_dim; y++ {
+		group := false
+		for x := range t.x_heads {
+			group = fill(fills, x, y, x, group)
+		}
+	}
+	fills = make([]levelFill, t.y_heads)
+	for x := t.x_heads; x < t.x_dim; x++ {
+		group := false
+		for y := range t.y_heads {
+			group = fill(fills, x, y, y, group)
+		}
+	}
+}
+
 // Creates a new TableData struct from provided data frame
 // Populates all TableData with specified number of row and col headers
 func NewTableData(df dataframe.DataFrame, y, x int) TableData {
-	out := TableData{}
-	out.populateDims(df)
-	out.populateCells(df)
+	return newTableData(df.Records(), y, x)
+}
+
+// Creates a new TableData struct from the records of a table, with the first record as its header row
+func newTableData(records [][]string, y, x int) TableData {
+	out := TableData{x_heads: x, y_heads: y}
+	out.populateDims(records)
+	out.populateCells(records)
 	out.populateRows(x)
 	out.populateColumns(y)
 	return out
 }
+
+// Creates a new TableData struct from a parsed table, applying the header counts and other table shaping options from the config
+// Returns an error if the config sets an unknown column type
+func NewTableDataFromConfig(t LabeledTable, c ConfigFields) (TableData, error) {
+	y, x := c.HeaderCounts(t)
+	out := newTableData(t.records(), y, x)
+	if c.FillHeaders {
+		out.fillHeads()
+		out.populateRows(x)
+		out.populateColumns(y)
+	}
+	out.keep_heads = c.IncludeHeaders
+	out.empty = newEmptyPolicy(c)
+	err := out.applyTypes(c.Types)
+	if err != nil {
+		return out, err
+	}
+	if c.Transpose {
+		out.transpose()
+	}
+	return out, nil
+}
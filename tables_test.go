@@ -8,32 +8,35 @@ package main
 import (
 	"fmt"
 	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
 )
 
 func reference_tables() (t1, t2, t3 TableData) {
 	// t1 0, 0
 	t1 = TableData{
 		cells: []DataValue{
-			{0, 0, []string{}, []string{}, "_"},
-			{1, 0, []string{}, []string{}, "col1"},
-			{2, 0, []string{}, []string{}, "col2"},
-			{3, 0, []string{}, []string{}, "col3"},
-			{0, 1, []string{}, []string{}, "row1"},
-			{1, 1, []string{}, []string{}, "val11"},
-			{2, 1, []string{}, []string{}, "val12"},
-			{3, 1, []string{}, []string{}, "val13"},
-			{0, 2, []string{}, []string{}, "row2"},
-			{1, 2, []string{}, []string{}, "val21"},
-			{2, 2, []string{}, []string{}, "val22"},
-			{3, 2, []string{}, []string{}, "val23"},
-			{0, 3, []string{}, []string{}, "row3"},
-			{1, 3, []string{}, []string{}, "val31"},
-			{2, 3, []string{}, []string{}, "val32"},
-			{3, 3, []string{}, []string{}, "val33"},
-			{0, 4, []string{}, []string{}, "row4"},
-			{1, 4, []string{}, []string{}, "val41"},
-			{2, 4, []string{}, []string{}, "val42"},
-			{3, 4, []string{}, []string{}, "val43"},
+			{0, 0, []string{}, []string{}, "_", TypedValue{TypeString, "_", ""}},
+			{1, 0, []string{}, []string{}, "col1", TypedValue{TypeString, "col1", ""}},
+			{2, 0, []string{}, []string{}, "col2", TypedValue{TypeString, "col2", ""}},
+			{3, 0, []string{}, []string{}, "col3", TypedValue{TypeString, "col3", ""}},
+			{0, 1, []string{}, []string{}, "row1", TypedValue{TypeString, "row1", ""}},
+			{1, 1, []string{}, []string{}, "val11", TypedValue{TypeString, "val11", ""}},
+			{2, 1, []string{}, []string{}, "val12", TypedValue{TypeString, "val12", ""}},
+			{3, 1, []string{}, []string{}, "val13", TypedValue{TypeString, "val13", ""}},
+			{0, 2, []string{}, []string{}, "row2", TypedValue{TypeString, "row2", ""}},
+			{1, 2, []string{}, []string{}, "val21", TypedValue{TypeString, "val21", ""}},
+			{2, 2, []string{}, []string{}, "val22", TypedValue{TypeString, "val22", ""}},
+			{3, 2, []string{}, []string{}, "val23", TypedValue{TypeString, "val23", ""}},
+			{0, 3, []string{}, []string{}, "row3", TypedValue{TypeString, "row3", ""}},
+			{1, 3, []string{}, []string{}, "val31", TypedValue{TypeString, "val31", ""}},
+			{2, 3, []string{}, []string{}, "val32", TypedValue{TypeString, "val32", ""}},
+			{3, 3, []string{}, []string{}, "val33", TypedValue{TypeString, "val33", ""}},
+			{0, 4, []string{}, []string{}, "row4", TypedValue{TypeString, "row4", ""}},
+			{1, 4, []string{}, []string{}, "val41", TypedValue{TypeString, "val41", ""}},
+			{2, 4, []string{}, []string{}, "val42", TypedValue{TypeString, "val42", ""}},
+			{3, 4, []string{}, []string{}, "val43", TypedValue{TypeString, "val43", ""}},
 		},
 		rows: [][]string{
 			{},
@@ -49,33 +52,35 @@ func reference_tables() (t1, t2, t3 TableData) {
 			{},
 			{},
 		},
-		x_dim: 4,
-		y_dim: 4,
+		x_dim:   4,
+		y_dim:   4,
+		x_heads: 0,
+		y_heads: 0,
 	}
 
 	// t2 0, 1
 	t2 = TableData{
 		cells: []DataValue{
-			{0, 0, []string{"_"}, []string{}, "_"},
-			{1, 0, []string{"_"}, []string{}, "col1"},
-			{2, 0, []string{"_"}, []string{}, "col2"},
-			{3, 0, []string{"_"}, []string{}, "col3"},
-			{0, 1, []string{"row1"}, []string{}, "row1"},
-			{1, 1, []string{"row1"}, []string{}, "val11"},
-			{2, 1, []string{"row1"}, []string{}, "val12"},
-			{3, 1, []string{"row1"}, []string{}, "val13"},
-			{0, 2, []string{"row2"}, []string{}, "row2"},
-			{1, 2, []string{"row2"}, []string{}, "val21"},
-			{2, 2, []string{"row2"}, []string{}, "val22"},
-			{3, 2, []string{"row2"}, []string{}, "val23"},
-			{0, 3, []string{"row3"}, []string{}, "row3"},
-			{1, 3, []string{"row3"}, []string{}, "val31"},
-			{2, 3, []string{"row3"}, []string{}, "val32"},
-			{3, 3, []string{"row3"}, []string{}, "val33"},
-			{0, 4, []string{"row4"}, []string{}, "row4"},
-			{1, 4, []string{"row4"}, []string{}, "val41"},
-			{2, 4, []string{"row4"}, []string{}, "val42"},
-			{3, 4, []string{"row4"}, []string{}, "val43"},
+			{0, 0, []string{"_"}, []string{}, "_", TypedValue{TypeString, "_", ""}},
+			{1, 0, []string{"_"}, []string{}, "col1", TypedValue{TypeString, "col1", ""}},
+			{2, 0, []string{"_"}, []string{}, "col2", TypedValue{TypeString, "col2", ""}},
+			{3, 0, []string{"_"}, []string{}, "col3", TypedValue{TypeString, "col3", ""}},
+			{0, 1, []string{"row1"}, []string{}, "row1", TypedValue{TypeString, "row1", ""}},
+			{1, 1, []string{"row1"}, []string{}, "val11", TypedValue{TypeString, "val11", ""}},
+			{2, 1, []string{"row1"}, []string{}, "val12", TypedValue{TypeString, "val12", ""}},
+			{3, 1, []string{"row1"}, []string{}, "val13", TypedValue{TypeString, "val13", ""}},
+			{0, 2, []string{"row2"}, []string{}, "row2", TypedValue{TypeString, "row2", ""}},
+			{1, 2, []string{"row2"}, []string{}, "val21", TypedValue{TypeString, "val21", ""}},
+			{2, 2, []string{"row2"}, []string{}, "val22", TypedValue{TypeString, "val22", ""}},
+			{3, 2, []string{"row2"}, []string{}, "val23", TypedValue{TypeString, "val23", ""}},
+			{0, 3, []string{"row3"}, []string{}, "row3", TypedValue{TypeString, "row3", ""}},
+			{1, 3, []string{"row3"}, []string{}, "val31", TypedValue{TypeString, "val31", ""}},
+			{2, 3, []string{"row3"}, []string{}, "val32", TypedValue{TypeString, "val32", ""}},
+			{3, 3, []string{"row3"}, []string{}, "val33", TypedValue{TypeString, "val33", ""}},
+			{0, 4, []string{"row4"}, []string{}, "row4", TypedValue{TypeString, "row4", ""}},
+			{1, 4, []string{"row4"}, []string{}, "val41", TypedValue{TypeString, "val41", ""}},
+			{2, 4, []string{"row4"}, []string{}, "val42", TypedValue{TypeString, "val42", ""}},
+			{3, 4, []string{"row4"}, []string{}, "val43", TypedValue{TypeString, "val43", ""}},
 		},
 		rows: [][]string{
 			{"_"},
@@ -91,33 +96,35 @@ func reference_tables() (t1, t2, t3 TableData) {
 			{},
 			{},
 		},
-		x_dim: 4,
-		y_dim: 4,
+		x_dim:   4,
+		y_dim:   4,
+		x_heads: 1,
+		y_heads: 0,
 	}
 
 	// t3 5, 5
 	t3 = TableData{
 		cells: []DataValue{
-			{0, 0, []string{"_"}, []string{"_"}, "_"},
-			{1, 0, []string{"_", "col1"}, []string{"col1"}, "col1"},
-			{2, 0, []string{"_", "col1", "col2"}, []string{"col2"}, "col2"},
-			{3, 0, []string{"_", "col1", "col2", "col3"}, []string{"col3"}, "col3"},
-			{0, 1, []string{"row1"}, []string{"_", "row1"}, "row1"},
-			{1, 1, []string{"row1", "val11"}, []string{"col1", "val11"}, "val11"},
-			{2, 1, []string{"row1", "val11", "val12"}, []string{"col2", "val12"}, "val12"},
-			{3, 1, []string{"row1", "val11", "val12", "val13"}, []string{"col3", "val13"}, "val13"},
-			{0, 2, []string{"row2"}, []string{"_", "row1", "row2"}, "row2"},
-			{1, 2, []string{"row2", "val21"}, []string{"col1", "val11", "val21"}, "val21"},
-			{2, 2, []string{"row2", "val21", "val22"}, []string{"col2", "val12", "val22"}, "val22"},
-			{3, 2, []string{"row2", "val21", "val22", "val23"}, []string{"col3", "val13", "val23"}, "val23"},
-			{0, 3, []string{"row3"}, []string{"_", "row1", "row2", "row3"}, "row3"},
-			{1, 3, []string{"row3", "val31"}, []string{"col1", "val11", "val21", "val31"}, "val31"},
-			{2, 3, []string{"row3", "val31", "val32"}, []string{"col2", "val12", "val22", "val32"}, "val32"},
-			{3, 3, []string{"row3", "val31", "val32", "val33"}, []string{"col3", "val13", "val23", "val33"}, "val33"},
-			{0, 4, []string{"row4"}, []string{"_", "row1", "row2", "row3", "row4"}, "row4"},
-			{1, 4, []string{"row4", "val41"}, []string{"col1", "val11", "val21", "val31", "val41"}, "val41"},
-			{2, 4, []string{"row4", "val41", "val42"}, []string{"col2", "val12", "val22", "val32", "val42"}, "val42"},
-			{3, 4, []string{"row4", "val41", "val42", "val43"}, []string{"col3", "val13", "val23", "val33", "val43"}, "val43"},
+			{0, 0, []string{"_"}, []string{"_"}, "_", TypedValue{TypeString, "_", ""}},
+			{1, 0, []string{"_", "col1"}, []string{"col1"}, "col1", TypedValue{TypeString, "col1", ""}},
+			{2, 0, []string{"_", "col1", "col2"}, []string{"col2"}, "col2", TypedValue{TypeString, "col2", ""}},
+			{3, 0, []string{"_", "col1", "col2", "col3"}, []string{"col3"}, "col3", TypedValue{TypeString, "col3", ""}},
+			{0, 1, []string{"row1"}, []string{"_", "row1"}, "row1", TypedValue{TypeString, "row1", ""}},
+			{1, 1, []string{"row1", "val11"}, []string{"col1", "val11"}, "val11", TypedValue{TypeString, "val11", ""}},
+			{2, 1, []string{"row1", "val11", "val12"}, []string{"col2", "val12"}, "val12", TypedValue{TypeString, "val12", ""}},
+			{3, 1, []string{"row1", "val11", "val12", "val13"}, []string{"col3", "val13"}, "val13", TypedValue{TypeString, "val13", ""}},
+			{0, 2, []string{"row2"}, []string{"_", "row1", "row2"}, "row2", TypedValue{TypeString, "row2", ""}},
+			{1, 2, []string{"row2", "val21"}, []string{"col1", "val11", "val21"}, "val21", TypedValue{TypeString, "val21", ""}},
+			{2, 2, []string{"row2", "val21", "val22"}, []string{"col2", "val12", "val22"}, "val22", TypedValue{TypeString, "val22", ""}},
+			{3, 2, []string{"row2", "val21", "val22", "val23"}, []string{"col3", "val13", "val23"}, "val23", TypedValue{TypeString, "val23", ""}},
+			{0, 3, []string{"row3"}, []string{"_", "row1", "row2", "row3"}, "row3", TypedValue{TypeString, "row3", ""}},
+			{1, 3, []string{"row3", "val31"}, []string{"col1", "val11", "val21", "val31"}, "val31", TypedValue{TypeString, "val31", ""}},
+			{2, 3, []string{"row3", "val31", "val32"}, []string{"col2", "val12", "val22", "val32"}, "val32", TypedValue{TypeString, "val32", ""}},
+			{3, 3, []string{"row3", "val31", "val32", "val33"}, []string{"col3", "val13", "val23", "val33"}, "val33", TypedValue{TypeString, "val33", ""}},
+			{0, 4, []string{"row4"}, []string{"_", "row1", "row2", "row3", "row4"}, "row4", TypedValue{TypeString, "row4", ""}},
+			{1, 4, []string{"row4", "val41"}, []string{"col1", "val11", "val21", "val31", "val41"}, "val41", TypedValue{TypeString, "val41", ""}},
+			{2, 4, []string{"row4", "val41", "val42"}, []string{"col2", "val12", "val22", "val32", "val42"}, "val42", TypedValue{TypeString, "val42", ""}},
+			{3, 4, []string{"row4", "val41", "val42", "val43"}, []string{"col3", "val13", "val23", "val33", "val43"}, "val43", TypedValue{TypeString, "val43", ""}},
 		},
 		rows: [][]string{
 			{"_", "col1", "col2", "col3"},
@@ -133,8 +140,10 @@ func reference_tables() (t1, t2, t3 TableData) {
 			{"col3", "val13", "val23", "val33", "val43"},
 			{},
 		},
-		x_dim: 4,
-		y_dim: 4,
+		x_dim:   4,
+		y_dim:   4,
+		x_heads: 5,
+		y_heads: 5,
 	}
 
 	return t1, t2, t3
@@ -147,9 +156,9 @@ func TestJoinHeaders(t *testing.T) {
 		exp1  string
 		exp2  string
 	}{
-		{DataValue{1, 2, []string{"abc", "def"}, []string{"123", "456"}, "value"}, ";", "abc;def", "123;456"},
-		{DataValue{0, 0, []string{"", "def"}, []string{"123", ""}, "value"}, "__", "__def", "123__"},
-		{DataValue{-2, -1, []string{"", ""}, []string{"", ""}, ""}, "", "", ""},
+		{DataValue{1, 2, []string{"abc", "def"}, []string{"123", "456"}, "value", TypedValue{TypeString, "value", ""}}, ";", "abc;def", "123;456"},
+		{DataValue{0, 0, []string{"", "def"}, []string{"123", ""}, "value", TypedValue{TypeString, "value", ""}}, "__", "__def", "123__"},
+		{DataValue{-2, -1, []string{"", ""}, []string{"", ""}, "", TypedValue{TypeString, "", ""}}, "", "", ""},
 	}
 
 	for _, test := range table {
@@ -163,6 +172,149 @@ func TestJoinHeaders(t *testing.T) {
 	}
 }
 
+func TestDescribeHeaders(t *testing.T) {
+	cell := DataValue{1, 2, []string{"east"}, []string{"2024", "Q1"}, "10", TypedValue{TypeString, "10", ""}}
+	table := []struct {
+		fields FormatFields
+		exp1   string
+		exp2   string
+	}{
+		{FormatFields{Delim: " / ", Eq: "is", XLabel: "region", YLabel: "period"}, "region is east", "period is 2024 / Q1"},
+		{FormatFields{Delim: " / ", Eq: "is", XLabel: "region", YLevels: []string{"year", "quarter"}}, "region is east", "year is 2024 and quarter is Q1"},
+		{FormatFields{Eq: "=", XLevels: []string{"region", "unused"}, YLevels: []string{"year"}}, "region = east", "year = 2024 and Q1"},
+		{FormatFields{Eq: "is", YLevels: []string{"", "quarter"}}, " is east", "2024 and quarter is Q1"},
+	}
+
+	for _, test := range table {
+		res1, res2 := cell.DescribeHeaders(test.fields)
+		if res1 != test.exp1 || res2 != test.exp2 {
+			t.Errorf("DescribeHeaders(%v) = %q, %q, expected %q, %q", test.fields, res1, res2, test.exp1, test.exp2)
+		}
+	}
+}
+
+func TestHeaderRow(t *testing.T) {
+	exp := []string{"X1", "X2", "var_0", "var_1", "2024", "2024", ""}
+	for _, normalize := range [][]string{nil, {NormalizeSpaces}} {
+		tables, err := ReadTables(ConfigFields{InFile: "data/test_colnames.csv", Parser: "CSV", Normalize: normalize})
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		td, err := NewTableDataFromConfig(tables[0], ConfigFields{NRowHeaders: ref_int(1), NColHeaders: ref_int(1)})
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		res := []string{}
+		for _, cell := range td.cells {
+			if cell.y == 0 {
+				res = append(res, cell.val)
+			}
+		}
+		if fmt.Sprint(res) != fmt.Sprint(exp) {
+			t.Errorf("NewTableDataFromConfig(%v) with normalize %v header row = %q, expected %q", tables[0].label, normalize, res, exp)
+		}
+	}
+}
+
+func TestFillHeads(t *testing.T) {
+	tables, err := ReadTables(ConfigFields{InFile: "data/test_grouped.csv", Parser: "CSV"})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	table := []struct {
+		fill bool
+		exp  []string
+	}{
+		{false, []string{
+			"[east north] [2024 Q1] 10", "[east north] [ Q2] 12", "[east north] [2025 Q1] 14", "[east north] [ Q2] 15",
+			"[ south] [2024 Q1] 8", "[ south] [ Q2] 9", "[ south] [2025 Q1] 11", "[ south] [ Q2] 13",
+			"[west north] [2024 Q1] 1", "[west north] [ Q2] 2", "[west north] [2025 Q1] 3", "[west north] [ Q2] 4",
+			"[ south] [2024 Q1] 5", "[ south] [ Q2] 6", "[ south] [2025 Q1] 7", "[ south] [ Q2] 8",
+		}},
+		{true, []string{
+			"[east north] [2024 Q1] 10", "[east north] [2024 Q2] 12", "[east north] [2025 Q1] 14", "[east north] [2025 Q2] 15",
+			"[east south] [2024 Q1] 8", "[east south] [2024 Q2] 9", "[east south] [2025 Q1] 11", "[east south] [2025 Q2] 13",
+			"[west north] [2024 Q1] 1", "[west north] [2024 Q2] 2", "[west north] [2025 Q1] 3", "[west north] [2025 Q2] 4",
+			"[west south] [2024 Q1] 5", "[west south] [2024 Q2] 6", "[west south] [2025 Q1] 7", "[west south] [2025 Q2] 8",
+		}},
+	}
+
+	for _, test := range table {
+		td, err := NewTableDataFromConfig(tables[0], ConfigFields{NRowHeaders: ref_int(2), NColHeaders: ref_int(2), FillHeaders: test.fill})
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		res := []string{}
+		for _, cell := range td.dataCells() {
+			res = append(res, fmt.Sprint(cell.x_head, " ", cell.y_head, " ", cell.val))
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("NewTableDataFromConfig(FillHeaders: %v) = %q, expected %q", test.fill, res, test.exp)
+		}
+	}
+}
+
+func TestLevelFill(t *testing.T) {
+	table := []struct {
+		vals   []string
+		groups []bool
+		exp    []string
+	}{
+		{[]string{"a", "", "", "b", ""}, []bool{false, false, false, false, false}, []string{"a", "a", "a", "b", "b"}},
+		{[]string{"", "a", ""}, []bool{false, false, false}, []string{"", "a", "a"}},
+		{[]string{"a", "", ""}, []bool{false, true, false}, []string{"a", "", ""}},
+		{[]string{"a", "", "b"}, []bool{false, true, true}, []string{"a", "", "b"}},
+	}
+
+	for _, test := range table {
+		fill := levelFill{}
+		res := []string{}
+		for i, val := range test.vals {
+			res = append(res, fill.next(val, test.groups[i]))
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("levelFill.next(%q, %v) = %q, expected %q", test.vals, test.groups, res, test.exp)
+		}
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	df, _, _ := reference_dataframes()
+	for _, heads := range [][2]int{{0, 0}, {1, 1}, {2, 1}} {
+		td := NewTableData(df, heads[0], heads[1])
+		res := NewTableData(df, heads[0], heads[1])
+		res.transpose()
+		if res.x_heads != td.y_heads || res.y_heads != td.x_heads || res.x_dim != td.y_dim+1 || res.y_dim != td.x_dim-1 {
+			t.Errorf("transpose() of %v table gave dims %v, %v and heads %v, %v", heads, res.x_dim, res.y_dim, res.x_heads, res.y_heads)
+		}
+		for _, cell := range res.cells {
+			src := td.cells[cell.x*td.x_dim+cell.y]
+			if cell.val != src.val || fmt.Sprint(cell.x_head) != fmt.Sprint(src.y_head) || fmt.Sprint(cell.y_head) != fmt.Sprint(src.x_head) {
+				t.Errorf("transpose() of %v table moved %v to %v", heads, src, cell)
+			}
+		}
+		res.transpose()
+		if fmt.Sprint(res) != fmt.Sprint(td) {
+			t.Errorf("transpose() twice of %v table = %v, expected %v", heads, res, td)
+		}
+	}
+
+	tables, err := ReadTables(ConfigFields{InFile: "data/test_specs.csv", Parser: "CSV"})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	config := ConfigFields{NRowHeaders: ref_int(1), NColHeaders: ref_int(1), Formatter: "NamedRowKeyValFormatter", Transpose: true, EmptyCells: EmptySkip}
+	fields := FormatFields{Link: "for", Eq: "is", XLabel: "attribute", YLabel: "product"}
+	res, _, err := FormatTables(tables, config, fields)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	exp := []string{"for product is widget, weight is 2kg, color is red, size is small", "for product is gadget, weight is 3kg, color is blue"}
+	if fmt.Sprint(res) != fmt.Sprint(exp) {
+		t.Errorf("FormatTables(%v) transposed = %q, expected %q", config.Formatter, res, exp)
+	}
+}
+
 func TestNewTableData(t *testing.T) {
 	df, _, _ := reference_dataframes()
 	t1, t2, t3 := reference_tables()
@@ -182,3 +334,66 @@ func TestNewTableData(t *testing.T) {
 		}
 	}
 }
+
+func TestDataCells(t *testing.T) {
+	t1, _, t3 := reference_tables()
+	table := []struct {
+		input TableData
+		exp   int
+	}{
+		{t1, 20},
+		{t3, 0},
+		{TableData{cells: t3.cells, x_heads: 5, y_heads: 5, keep_heads: true}, 20},
+		{TableData{cells: t3.cells, x_heads: 1, y_heads: 1}, 12},
+		{TableData{cells: t3.cells, x_heads: 1}, 15},
+		{TableData{cells: t3.cells, y_heads: 2}, 12},
+	}
+
+	for _, test := range table {
+		res := test.input.dataCells()
+		if len(res) != test.exp {
+			t.Errorf("dataCells() returned %v cells, expected %v", len(res), test.exp)
+		}
+		for _, cell := range res {
+			if test.input.isHead(cell) && !test.input.keep_heads {
+				t.Errorf("dataCells() returned header cell %v", cell)
+			}
+		}
+	}
+}
+
+func TestApplyTypes(t *testing.T) {
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"item", "price", "count"},
+			{"pizza", "12", "3"},
+			{"wings", "$5.50", "4"},
+		}, dataframe.DetectTypes(false), dataframe.DefaultType(series.String))
+	inferred := []ValueType{TypeString, TypeString, TypeString, TypeString, TypeInt, TypeInt, TypeString, TypeCurrency, TypeInt}
+	table := []struct {
+		types map[string]string
+		exp   []ValueType
+		err   bool
+	}{
+		{nil, inferred, false},
+		{map[string]string{"price": "currency"}, []ValueType{TypeString, TypeString, TypeString, TypeString, TypeString, TypeInt, TypeString, TypeCurrency, TypeInt}, false},
+		{map[string]string{"2": "string", "price": "decimal"}, []ValueType{TypeString, TypeString, TypeString, TypeString, TypeDecimal, TypeString, TypeString, TypeString, TypeString}, false},
+		{map[string]string{"price": "money"}, inferred, true},
+		{map[string]string{"2": "int", "price": "Currency"}, inferred, true},
+	}
+
+	for _, test := range table {
+		td := NewTableData(df, 1, 1)
+		err := td.applyTypes(test.types)
+		if (err != nil) != test.err {
+			t.Errorf("applyTypes(%v) returned error %v, expected error %v", test.types, err, test.err)
+		}
+		res := []ValueType{}
+		for _, cell := range td.cells {
+			res = append(res, cell.typed.kind)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("applyTypes(%v) = %v, expected %v", test.types, res, test.exp)
+		}
+	}
+}
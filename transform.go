@@ -0,0 +1,162 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 04:31:52 AM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Transforms reshaping a table between wide and long form
+const (
+	// Turns each non id column into rows of id, variable, and value, so a table with one column per month gets one row per month
+	TransformMelt = "melt"
+	// Turns rows of id, variable, and value into one column per variable, the reverse of melt
+	TransformPivot = "pivot"
+)
+
+// Column names used for melted tables when the config doesn't give its own
+const (
+	DefaultVariableColumn = "variable"
+	DefaultValueColumn    = "value"
+)
+
+// Handles options for reshaping tables after they are read and before they are formatted
+type TransformOptions struct {
+	// Either "melt" to turn a wide table into long form, or "pivot" to turn a long table into wide form. Tables are left as read when unset
+	Type string `json:"type,omitempty"`
+	// Columns identifying the entity each row describes, which are kept as they are
+	ID []string `json:"id,omitempty"`
+	// Column holding the names of melted columns, or whose values name the pivoted columns. Defaults to "variable"
+	Variable string `json:"variable,omitempty"`
+	// Column holding the values of melted columns, or the values spread across the pivoted columns. Defaults to "value"
+	Value string `json:"value,omitempty"`
+}
+
+// Finds the index of each named column, reporting the available columns when one is missing
+func columnIndices(colnames []string, names []string) ([]int, error) {
+	out := []int{}
+	for _, name := range names {
+		i := slices.Index(colnames, name)
+		if i == -1 {
+			return nil, fmt.Errorf("column %q not found, columns are %s", name, strings.Join(colnames, ", "))
+		}
+		out = append(out, i)
+	}
+	return out, nil
+}
+
+// Melts records with a header row into long form, with one row for each value in a non id column
+// Rows list the id columns, the name of the melted column, and its value, keeping the order of rows and then columns
+func meltRecords(records [][]string, o TransformOptions) ([][]string, error) {
+	ids, err := columnIndices(records[0], o.ID)
+	if err != nil {
+		return nil, err
+	}
+	head := append(slices.Clone(o.ID), o.Variable, o.Value)
+	out := [][]string{head}
+	for _, row := range records[1:] {
+		id_vals := []string{}
+		for _, i := range ids {
+			id_vals = append(id_vals, row[i])
+		}
+		for i, name := range records[0] {
+			if slices.Contains(ids, i) {
+				continue
+			}
+			out = append(out, append(slices.Clone(id_vals), name, row[i]))
+		}
+	}
+	return out, nil
+}
+
+// Pivots records with a header row into wide form, with one row for each combination of id values and one column for each variable
+// Rows and columns keep the order they first appear in, cells without a value are left blank, and columns other than the id,
+// variable, and value columns are dropped
+func pivotRecords(records [][]string, o TransformOptions) ([][]string, error) {
+	cols, err := columnIndices(records[0], append(slices.Clone(o.ID), o.Variable, o.Value))
+	if err != nil {
+		return nil, err
+	}
+	ids, variable, value := cols[:len(o.ID)], cols[len(o.ID)], cols[len(o.ID)+1]
+
+	variables := []string{}
+	keys := []string{}
+	rows := map[string][]string{}
+	cells := map[[2]string]int{}
+	for y, row := range records[1:] {
+		id_vals := []string{}
+		for _, i := range ids {
+			id_vals = append(id_vals, row[i])
+		}
+		key := strings.Join(id_vals, "\x00")
+		if _, ok := rows[key]; !ok {
+			keys = append(keys, key)
+			rows[key] = id_vals
+		}
+		if !slices.Contains(variables, row[variable]) {
+			variables = append(variables, row[variable])
+		}
+		cell := [2]string{key, row[variable]}
+		if prev, ok := cells[cell]; ok {
+			return nil, fmt.Errorf("rows %d and %d both give a value for %s %q of %s", prev+1, y+1, o.Variable, row[variable], strings.Join(id_vals, ", "))
+		}
+		cells[cell] = y
+	}
+
+	out := [][]string{append(slices.Clone(o.ID), variables...)}
+	for _, key := range keys {
+		row := slices.Clone(rows[key])
+		for _, v := range variables {
+			val := ""
+			if y, ok := cells[[2]string{key, v}]; ok {
+				val = records[y+1][value]
+			}
+			row = append(row, val)
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// Reshapes a table with the configured transform, leaving it as read when no transform is set
+// Transformed tables count their id columns, and for melted tables the variable column, as row headers, along with a single
+// header row, which are used unless the config sets its own header counts
+func transformTable(t LabeledTable, o TransformOptions) (LabeledTable, error) {
+	if o.Type == "" {
+		return t, nil
+	}
+	if o.Variable == "" {
+		o.Variable = DefaultVariableColumn
+	}
+	if o.Value == "" {
+		o.Value = DefaultValueColumn
+	}
+	records := t.records()
+	if len(records) == 0 {
+		return t, nil
+	}
+
+	var err error
+	switch o.Type {
+	case TransformMelt:
+		records, err = meltRecords(records, o)
+		t.row_headers = len(o.ID) + 1
+	case TransformPivot:
+		records, err = pivotRecords(records, o)
+		t.row_headers = len(o.ID)
+	default:
+		return t, fmt.Errorf("unknown transform %q, expected %q or %q", o.Type, TransformMelt, TransformPivot)
+	}
+	if err != nil {
+		return t, fmt.Errorf("unable to %s table: %w", o.Type, err)
+	}
+	t.col_headers = 1
+	transformed, err := loadTable(records)
+	t.df, t.header = transformed.df, transformed.header
+	return t, err
+}
@@ -0,0 +1,140 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 04:31:52 AM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMeltRecords(t *testing.T) {
+	records := [][]string{{"region", "jan", "feb"}, {"east", "10", "12"}, {"west", "8", ""}}
+	table := []struct {
+		options TransformOptions
+		exp     [][]string
+		err     bool
+	}{
+		{TransformOptions{ID: []string{"region"}, Variable: "month", Value: "sales"}, [][]string{
+			{"region", "month", "sales"}, {"east", "jan", "10"}, {"east", "feb", "12"}, {"west", "jan", "8"}, {"west", "feb", ""},
+		}, false},
+		{TransformOptions{ID: []string{"region", "jan"}, Variable: "month", Value: "sales"}, [][]string{
+			{"region", "jan", "month", "sales"}, {"east", "10", "feb", "12"}, {"west", "8", "feb", ""},
+		}, false},
+		{TransformOptions{Variable: "column", Value: "cell"}, [][]string{
+			{"column", "cell"}, {"region", "east"}, {"jan", "10"}, {"feb", "12"}, {"region", "west"}, {"jan", "8"}, {"feb", ""},
+		}, false},
+		{TransformOptions{ID: []string{"country"}}, nil, true},
+	}
+
+	for _, test := range table {
+		res, err := meltRecords(records, test.options)
+		if (err != nil) != test.err {
+			t.Errorf("meltRecords(%+v) returned error %v, expected error %v", test.options, err, test.err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("meltRecords(%+v) = %q, expected %q", test.options, res, test.exp)
+		}
+	}
+}
+
+func TestPivotRecords(t *testing.T) {
+	records := [][]string{{"region", "month", "sales"}, {"east", "jan", "10"}, {"west", "feb", "9"}, {"east", "feb", "12"}, {"west", "jan", "8"}, {"north", "mar", "1"}}
+	table := []struct {
+		input   [][]string
+		options TransformOptions
+		exp     [][]string
+		err     bool
+	}{
+		{records, TransformOptions{ID: []string{"region"}, Variable: "month", Value: "sales"}, [][]string{
+			{"region", "jan", "feb", "mar"}, {"east", "10", "12", ""}, {"west", "8", "9", ""}, {"north", "", "", "1"},
+		}, false},
+		{records, TransformOptions{Variable: "month", Value: "sales"}, nil, true},
+		{records, TransformOptions{ID: []string{"region"}, Variable: "quarter", Value: "sales"}, nil, true},
+		{[][]string{{"a", "b", "c"}, {"1", "x", "2"}, {"1", "y", "3"}, {"2", "x", "4"}}, TransformOptions{Variable: "b", Value: "c"}, nil, true},
+		{[][]string{{"a", "b", "c"}, {"1", "x", "2"}, {"1", "y", "3"}}, TransformOptions{Variable: "b", Value: "c"}, [][]string{{"x", "y"}, {"2", "3"}}, false},
+	}
+
+	for _, test := range table {
+		res, err := pivotRecords(test.input, test.options)
+		if (err != nil) != test.err {
+			t.Errorf("pivotRecords(%+v) returned error %v, expected error %v", test.options, err, test.err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("pivotRecords(%+v) = %q, expected %q", test.options, res, test.exp)
+		}
+	}
+}
+
+func TestTransformTable(t *testing.T) {
+	fields := FormatFields{Delim: " / ", Link: "for", Eq: "is", XLabel: "region"}
+	table := []struct {
+		config ConfigFields
+		exp    []string
+	}{
+		{ConfigFields{InFile: "data/test_wide.csv", NRowHeaders: ref_int(1), NColHeaders: ref_int(1)}, []string{
+			"for region is east, jan is 10", "for region is east, feb is 12", "for region is east, mar is 14",
+			"for region is west, jan is 8", "for region is west, feb is 9", "for region is west, mar is",
+		}},
+		{ConfigFields{InFile: "data/test_long.csv", Transform: TransformOptions{TransformPivot, []string{"region"}, "month", "sales"}}, []string{
+			"for region is east, jan is 10", "for region is east, feb is 12", "for region is east, mar is 14",
+			"for region is west, jan is 8", "for region is west, feb is 9", "for region is west, mar is",
+		}},
+		{ConfigFields{InFile: "data/test_wide.csv", Transform: TransformOptions{Type: TransformMelt, ID: []string{"region"}}}, []string{
+			"for region is east / jan, value is 10", "for region is east / feb, value is 12", "for region is east / mar, value is 14",
+			"for region is west / jan, value is 8", "for region is west / feb, value is 9", "for region is west / mar, value is",
+		}},
+		{ConfigFields{InFile: "data/test_wide.csv", Transform: TransformOptions{TransformMelt, []string{"region"}, "month", "sales"}, NRowHeaders: ref_int(1), EmptyCells: EmptySkip}, []string{
+			"for region is east, month is jan", "for region is east, sales is 10", "for region is east, month is feb", "for region is east, sales is 12",
+			"for region is east, month is mar", "for region is east, sales is 14", "for region is west, month is jan", "for region is west, sales is 8",
+			"for region is west, month is feb", "for region is west, sales is 9", "for region is west, month is mar",
+		}},
+	}
+
+	for _, test := range table {
+		test.config.Parser, test.config.Formatter = "CSV", "NamedRowFormatter"
+		tables, err := ReadTables(test.config)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		res, _, err := FormatTables(tables, test.config, fields)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("FormatTables(%v, %+v) = %q, expected %q", test.config.InFile, test.config.Transform, res, test.exp)
+		}
+	}
+
+	_, err := ReadTables(ConfigFields{InFile: "data/test_wide.csv", Parser: "CSV", Transform: TransformOptions{Type: "unpivot"}})
+	if err == nil {
+		t.Errorf("ReadTables() with transform %q returned no error, expected an error", "unpivot")
+	}
+}
+
+func TestTransformColnames(t *testing.T) {
+	table := []struct {
+		options TransformOptions
+		exp     [][]string
+	}{
+		{TransformOptions{Type: TransformMelt, ID: []string{"X1"}}, [][]string{
+			{"X1", "variable", "value"}, {"a", "X2", "1"}, {"a", "var_0", "2"}, {"a", "var_1", "3"}, {"a", "2024", "4"}, {"a", "2024", "5"}, {"a", "", "6"},
+		}},
+		{TransformOptions{Type: TransformMelt, ID: []string{"var_0", "X2"}}, [][]string{
+			{"var_0", "X2", "variable", "value"}, {"2", "1", "X1", "a"}, {"2", "1", "var_1", "3"}, {"2", "1", "2024", "4"}, {"2", "1", "2024", "5"}, {"2", "1", "", "6"},
+		}},
+	}
+
+	for _, test := range table {
+		tables, err := ReadTables(ConfigFields{InFile: "data/test_colnames.csv", Parser: "CSV", Transform: test.options})
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		res := tables[0].records()
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("ReadTables() with transform %+v = %q, expected %q", test.options, res, test.exp)
+		}
+	}
+}
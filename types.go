@@ -0,0 +1,215 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 04:17:52 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type of a cell value, either inferred from its text or set per column in the config
+type ValueType string
+
+const (
+	TypeString   ValueType = "string"
+	TypeInt      ValueType = "int"
+	TypeDecimal  ValueType = "decimal"
+	TypeBool     ValueType = "bool"
+	TypeDate     ValueType = "date"
+	TypeCurrency ValueType = "currency"
+	TypePercent  ValueType = "percent"
+)
+
+// Every ValueType that can be set for a column
+var valueTypes = []ValueType{TypeString, TypeInt, TypeDecimal, TypeBool, TypeDate, TypeCurrency, TypePercent}
+
+// Checks that the type configured for each column is a known ValueType
+func checkTypes(types map[string]string) error {
+	cols := []string{}
+	for col := range types {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	for _, col := range cols {
+		if !slices.Contains(valueTypes, ValueType(types[col])) {
+			names := []string{}
+			for _, t := range valueTypes {
+				names = append(names, string(t))
+			}
+			return fmt.Errorf("unknown type %q for column %q, expected one of %s", types[col], col, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// Represents a cell value parsed according to its type
+type TypedValue struct {
+	// Type of the value
+	kind ValueType
+	// Parsed value, as an int64 for TypeInt, float64 for TypeDecimal/TypeCurrency/TypePercent, bool for TypeBool, time.Time for TypeDate, and string otherwise
+	parsed any
+	// Currency symbol or code for TypeCurrency, and % for TypePercent
+	unit string
+}
+
+// Number with optional sign, thousands separators, and decimal part
+var numberPattern = regexp.MustCompile(`^[+-]?(\d{1,3}(,\d{3})+|\d+)?(\.\d+)?$`)
+
+// ISO 4217 codes of widely used currencies, listed rather than matched as any three capitals so text such as "GPU 4090" isn't read as currency
+var currencyCodes = []string{
+	"USD", "EUR", "GBP", "JPY", "CNY", "INR", "KRW", "CHF", "CAD", "AUD", "NZD", "HKD", "SGD", "TWD", "SEK", "NOK", "DKK", "PLN",
+	"CZK", "HUF", "RON", "BGN", "TRY", "RUB", "UAH", "ILS", "AED", "SAR", "QAR", "KWD", "EGP", "ZAR", "NGN", "KES", "MXN", "BRL",
+	"ARS", "CLP", "COP", "PEN", "THB", "MYR", "IDR", "PHP", "VND", "PKR", "BDT", "ISK",
+}
+
+// Currency symbols and ISO codes recognized before or after a number
+var currencyUnit = `[$€£¥₹₩]|` + strings.Join(currencyCodes, "|")
+var currencyPattern = regexp.MustCompile(`^(-|\()?\s*(` + currencyUnit + `)?\s*(-)?\s*([\d.,]+)\s*(` + currencyUnit + `)?\s*\)?$`)
+
+// Date layouts tried in order, with month first before day first for ambiguous numeric dates
+var dateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006/01/02",
+	"01/02/2006",
+	"02/01/2006",
+	"1/2/2006",
+	"2/1/2006",
+	"02.01.2006",
+	"2.1.2006",
+	"02-01-2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"02-Jan-2006",
+}
+
+// Parses a number written with optional thousands separators
+func parseNumber(s string) (float64, bool) {
+	if s == "" || s == "+" || s == "-" || !numberPattern.MatchString(s) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	return f, err == nil
+}
+
+func parseInt(s string) (TypedValue, bool) {
+	if strings.Contains(s, ".") || !numberPattern.MatchString(s) {
+		return TypedValue{}, false
+	}
+	i, err := strconv.ParseInt(strings.ReplaceAll(s, ",", ""), 10, 64)
+	if err != nil {
+		return TypedValue{}, false
+	}
+	return TypedValue{TypeInt, i, ""}, true
+}
+
+func parseDecimal(s string) (TypedValue, bool) {
+	f, ok := parseNumber(s)
+	if !ok {
+		return TypedValue{}, false
+	}
+	return TypedValue{TypeDecimal, f, ""}, true
+}
+
+func parseBool(s string) (TypedValue, bool) {
+	switch strings.ToLower(s) {
+	case "true", "yes":
+		return TypedValue{TypeBool, true, ""}, true
+	case "false", "no":
+		return TypedValue{TypeBool, false, ""}, true
+	}
+	return TypedValue{}, false
+}
+
+func parseDate(s string) (TypedValue, bool) {
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return TypedValue{TypeDate, t, ""}, true
+		}
+	}
+	return TypedValue{}, false
+}
+
+// Parses amounts such as $12.00, -€5, (£3.50), 1,200 USD, and EUR 7
+func parseCurrency(s string) (TypedValue, bool) {
+	m := currencyPattern.FindStringSubmatch(s)
+	if m == nil || (m[2] == "") == (m[5] == "") {
+		return TypedValue{}, false
+	}
+	if (m[1] == "(") != strings.HasSuffix(s, ")") {
+		return TypedValue{}, false
+	}
+	f, ok := parseNumber(m[4])
+	if !ok {
+		return TypedValue{}, false
+	}
+	if m[1] != "" || m[3] != "" {
+		f = -f
+	}
+	return TypedValue{TypeCurrency, f, m[2] + m[5]}, true
+}
+
+func parsePercent(s string) (TypedValue, bool) {
+	num, found := strings.CutSuffix(s, "%")
+	if !found {
+		return TypedValue{}, false
+	}
+	f, ok := parseNumber(strings.TrimSpace(num))
+	if !ok {
+		return TypedValue{}, false
+	}
+	return TypedValue{TypePercent, f, "%"}, true
+}
+
+// Parses a cell value as the specified type, returning an error if the value doesn't match it
+func ParseAs(s string, t ValueType) (TypedValue, error) {
+	v := strings.TrimSpace(s)
+	var out TypedValue
+	ok := false
+	switch t {
+	case TypeString:
+		out, ok = TypedValue{TypeString, s, ""}, true
+	case TypeInt:
+		out, ok = parseInt(v)
+	case TypeDecimal:
+		out, ok = parseDecimal(v)
+	case TypeBool:
+		out, ok = parseBool(v)
+	case TypeDate:
+		out, ok = parseDate(v)
+	case TypeCurrency:
+		out, ok = parseCurrency(v)
+	case TypePercent:
+		out, ok = parsePercent(v)
+	default:
+		return TypedValue{}, fmt.Errorf("unknown value type %q", t)
+	}
+	if !ok {
+		return TypedValue{}, fmt.Errorf("%q is not a valid %s", s, t)
+	}
+	return out, nil
+}
+
+// Infers the type of a cell value, falling back to TypeString for anything unrecognized
+func InferType(s string) TypedValue {
+	for _, t := range []ValueType{TypeBool, TypeInt, TypeDecimal, TypePercent, TypeCurrency, TypeDate} {
+		out, err := ParseAs(s, t)
+		if err == nil {
+			return out
+		}
+	}
+	return TypedValue{TypeString, s, ""}
+}
@@ -0,0 +1,86 @@
+// -*- coding: utf-8 -*-
+
+// Created on Sun Oct 18 04:17:52 PM EDT 2026
+// author: Ryan Hildebrandt, github.com/ryancahildebrandt
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestInferType(t *testing.T) {
+	table := []struct {
+		input string
+		exp   TypedValue
+	}{
+		{"", TypedValue{TypeString, "", ""}},
+		{"val11", TypedValue{TypeString, "val11", ""}},
+		{"12", TypedValue{TypeInt, int64(12), ""}},
+		{"-1,200", TypedValue{TypeInt, int64(-1200), ""}},
+		{" 7 ", TypedValue{TypeInt, int64(7), ""}},
+		{"3.25", TypedValue{TypeDecimal, 3.25, ""}},
+		{"1,234.5", TypedValue{TypeDecimal, 1234.5, ""}},
+		{"1,23", TypedValue{TypeString, "1,23", ""}},
+		{"TRUE", TypedValue{TypeBool, true, ""}},
+		{"no", TypedValue{TypeBool, false, ""}},
+		{"12.5%", TypedValue{TypePercent, 12.5, "%"}},
+		{"-3 %", TypedValue{TypePercent, -3.0, "%"}},
+		{"$12.00", TypedValue{TypeCurrency, 12.0, "$"}},
+		{"-€5", TypedValue{TypeCurrency, -5.0, "€"}},
+		{"(£3.50)", TypedValue{TypeCurrency, -3.5, "£"}},
+		{"1,200 USD", TypedValue{TypeCurrency, 1200.0, "USD"}},
+		{"EUR 7", TypedValue{TypeCurrency, 7.0, "EUR"}},
+		{"$12 USD", TypedValue{TypeString, "$12 USD", ""}},
+		{"GPU 4090", TypedValue{TypeString, "GPU 4090", ""}},
+		{"ABC 12", TypedValue{TypeString, "ABC 12", ""}},
+		{"USB 3", TypedValue{TypeString, "USB 3", ""}},
+		{"12 kWh", TypedValue{TypeString, "12 kWh", ""}},
+		{"JPY 1,500", TypedValue{TypeCurrency, 1500.0, "JPY"}},
+		{"£3.50)", TypedValue{TypeString, "£3.50)", ""}},
+		{"2024-07-21", TypedValue{TypeDate, time.Date(2024, 7, 21, 0, 0, 0, 0, time.UTC), ""}},
+		{"2024-07-21T16:00:25Z", TypedValue{TypeDate, time.Date(2024, 7, 21, 16, 0, 25, 0, time.UTC), ""}},
+		{"07/02/2024", TypedValue{TypeDate, time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC), ""}},
+		{"21/07/2024", TypedValue{TypeDate, time.Date(2024, 7, 21, 0, 0, 0, 0, time.UTC), ""}},
+		{"21.07.2024", TypedValue{TypeDate, time.Date(2024, 7, 21, 0, 0, 0, 0, time.UTC), ""}},
+		{"July 21, 2024", TypedValue{TypeDate, time.Date(2024, 7, 21, 0, 0, 0, 0, time.UTC), ""}},
+		{"21 Jul 2024", TypedValue{TypeDate, time.Date(2024, 7, 21, 0, 0, 0, 0, time.UTC), ""}},
+	}
+
+	for _, test := range table {
+		res := InferType(test.input)
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) || fmt.Sprintf("%T", res.parsed) != fmt.Sprintf("%T", test.exp.parsed) {
+			t.Errorf("InferType(%q) = %v, expected %v", test.input, res, test.exp)
+		}
+	}
+}
+
+func TestParseAs(t *testing.T) {
+	table := []struct {
+		input string
+		kind  ValueType
+		exp   TypedValue
+		err   bool
+	}{
+		{"12", TypeString, TypedValue{TypeString, "12", ""}, false},
+		{"12", TypeDecimal, TypedValue{TypeDecimal, 12.0, ""}, false},
+		{"12", TypeCurrency, TypedValue{}, true},
+		{"yes", TypeBool, TypedValue{TypeBool, true, ""}, false},
+		{"yes", TypeInt, TypedValue{}, true},
+		{"price", TypeCurrency, TypedValue{}, true},
+		{"GPU 4090", TypeCurrency, TypedValue{}, true},
+		{"12", "number", TypedValue{}, true},
+	}
+
+	for _, test := range table {
+		res, err := ParseAs(test.input, test.kind)
+		if (err != nil) != test.err {
+			t.Errorf("ParseAs(%q, %v) returned error %v, expected error %v", test.input, test.kind, err, test.err)
+		}
+		if fmt.Sprint(res) != fmt.Sprint(test.exp) {
+			t.Errorf("ParseAs(%q, %v) = %v, expected %v", test.input, test.kind, res, test.exp)
+		}
+	}
+}